@@ -0,0 +1,290 @@
+package adminserver
+
+import (
+	"autonity-oracle/types"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hashicorp/go-hclog"
+	"github.com/modern-go/reflect2"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// The endpoints of the admin API, the plugin and the symbol are selected by the query parameters `name` and `symbol`,
+// the number of rounds is selected by the query parameter `n`.
+const (
+	PluginsPath      = "/admin/plugins"
+	SamplesPath      = "/admin/samples"
+	RoundPath        = "/admin/round"
+	RoundDataPath    = "/admin/rounds"
+	ShadowPath       = "/admin/shadow"
+	AccuracyPath     = "/admin/accuracy"
+	ReloadPluginPath = "/admin/plugins/reload"
+	UnloadPluginPath = "/admin/plugins/unload"
+)
+
+var (
+	DefaultRounds    = types.MaxBufferedRounds
+	ShutdownDuration = 5 * time.Second
+)
+
+// Backend is the oracle server whose states and plugins are exposed by the admin API.
+type Backend interface {
+	Plugins() ([]types.PluginInfo, error)
+	Samples(name string) (map[string][]types.Price, error)
+	RoundState() (types.RoundState, error)
+	RecentRoundData(n int) ([]types.RoundData, error)
+	ShadowReports(n int) ([]types.DeviationReport, error)
+	Accuracy() (types.AccuracyReport, error)
+	ReloadPlugin(name string) error
+	UnloadPlugin(name string) error
+}
+
+// roundData is the view of a types.RoundData, the vote is presented by its transaction hash, and the salt is presented
+// only once the commitment of the round is revealed, that is by the vote of the next round.
+type roundData struct {
+	RoundID        uint64              `json:"round"`
+	TxHash         *common.Hash        `json:"txHash,omitempty"`
+	Salt           *big.Int            `json:"salt,omitempty"`
+	CommitmentHash common.Hash         `json:"commitmentHash"`
+	Prices         types.PriceBySymbol `json:"prices"`
+	Symbols        []string            `json:"symbols"`
+	Derivations    map[string][]string `json:"derivations,omitempty"`
+	Confirmed      bool                `json:"confirmed"`
+}
+
+// AdminServer exposes a local JSON API for the operators to inspect and to manage the oracle server on runtime. It is
+// not authenticated, thus it should only listen on a local interface.
+type AdminServer struct {
+	logger  hclog.Logger
+	backend Backend
+	srv     *http.Server
+}
+
+func NewAdminServer(logLevel hclog.Level, addr string, backend Backend) *AdminServer {
+	as := &AdminServer{backend: backend}
+	as.logger = hclog.New(&hclog.LoggerOptions{
+		Name:   reflect2.TypeOfPtr(as).String(),
+		Output: os.Stdout,
+		Level:  logLevel,
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PluginsPath, as.get(as.plugins))
+	mux.HandleFunc(SamplesPath, as.get(as.samples))
+	mux.HandleFunc(RoundPath, as.get(as.round))
+	mux.HandleFunc(RoundDataPath, as.get(as.roundData))
+	mux.HandleFunc(ShadowPath, as.get(as.shadowReports))
+	mux.HandleFunc(AccuracyPath, as.get(as.accuracy))
+	mux.HandleFunc(ReloadPluginPath, as.post(as.reloadPlugin))
+	mux.HandleFunc(UnloadPluginPath, as.post(as.unloadPlugin))
+	as.srv = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: ShutdownDuration,
+	}
+	return as
+}
+
+// Start runs the HTTP listener in a routine, an error of the listener is logged without stopping the oracle server.
+func (as *AdminServer) Start() {
+	go func() {
+		as.logger.Info("admin server is listening", "addr", as.srv.Addr)
+		if err := as.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			as.logger.Error("admin server", "error", err.Error())
+		}
+	}()
+}
+
+func (as *AdminServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownDuration)
+	defer cancel()
+	if err := as.srv.Shutdown(ctx); err != nil {
+		as.logger.Error("stop admin server", "error", err.Error())
+	}
+}
+
+type handler func(r *http.Request) (interface{}, error)
+
+func (as *AdminServer) get(h handler) http.HandlerFunc {
+	return as.serve(http.MethodGet, h)
+}
+
+func (as *AdminServer) post(h handler) http.HandlerFunc {
+	return as.serve(http.MethodPost, h)
+}
+
+// serve writes the result of the handler in JSON, an error is written as {"error": "..."} with a status code of it.
+func (as *AdminServer) serve(method string, h handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != method {
+			as.write(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		result, err := h(r)
+		if err != nil {
+			as.logger.Debug("admin API call", "path", r.URL.Path, "error", err.Error())
+			as.write(w, statusOf(err), map[string]string{"error": err.Error()})
+			return
+		}
+		as.write(w, http.StatusOK, result)
+	}
+}
+
+func (as *AdminServer) write(w http.ResponseWriter, status int, v interface{}) {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		as.logger.Error("write admin API response", "error", err.Error())
+	}
+}
+
+func (as *AdminServer) plugins(_ *http.Request) (interface{}, error) {
+	return as.backend.Plugins()
+}
+
+func (as *AdminServer) samples(r *http.Request) (interface{}, error) {
+	name, err := requiredParam(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	samples, err := as.backend.Samples(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+		return map[string][]types.Price{symbol: samples[symbol]}, nil
+	}
+	return samples, nil
+}
+
+func (as *AdminServer) round(_ *http.Request) (interface{}, error) {
+	return as.backend.RoundState()
+}
+
+func (as *AdminServer) roundData(r *http.Request) (interface{}, error) {
+	n, err := numOfRounds(r)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := as.backend.RoundState()
+	if err != nil {
+		return nil, err
+	}
+
+	rounds, err := as.backend.RecentRoundData(n)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]roundData, 0, len(rounds))
+	for _, rd := range rounds {
+		view := roundData{
+			RoundID:        rd.RoundID,
+			CommitmentHash: rd.CommitmentHash,
+			Prices:         rd.Prices,
+			Symbols:        rd.Symbols,
+			Derivations:    rd.Derivations,
+			Confirmed:      rd.Confirmed,
+		}
+		if rd.RoundID < state.Round {
+			view.Salt = rd.Salt
+		}
+		if rd.Tx != nil {
+			hash := rd.Tx.Hash()
+			view.TxHash = &hash
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func (as *AdminServer) shadowReports(r *http.Request) (interface{}, error) {
+	n, err := numOfRounds(r)
+	if err != nil {
+		return nil, err
+	}
+	return as.backend.ShadowReports(n)
+}
+
+func (as *AdminServer) accuracy(_ *http.Request) (interface{}, error) {
+	return as.backend.Accuracy()
+}
+
+func (as *AdminServer) reloadPlugin(r *http.Request) (interface{}, error) {
+	name, err := requiredParam(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = as.backend.ReloadPlugin(name); err != nil {
+		return nil, err
+	}
+	as.logger.Info("plugin is reloaded", "name", name)
+	return map[string]string{"reloaded": name}, nil
+}
+
+func (as *AdminServer) unloadPlugin(r *http.Request) (interface{}, error) {
+	name, err := requiredParam(r, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = as.backend.UnloadPlugin(name); err != nil {
+		return nil, err
+	}
+	as.logger.Info("plugin is unloaded", "name", name)
+	return map[string]string{"unloaded": name}, nil
+}
+
+// errBadRequest is an invalid parameter of an admin API call.
+type errBadRequest struct {
+	msg string
+}
+
+func (e errBadRequest) Error() string {
+	return e.msg
+}
+
+func requiredParam(r *http.Request, key string) (string, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return "", errBadRequest{"missing query parameter: " + key}
+	}
+	return v, nil
+}
+
+func numOfRounds(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("n")
+	if v == "" {
+		return DefaultRounds, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, errBadRequest{"invalid number of rounds: " + v}
+	}
+	return n, nil
+}
+
+func statusOf(err error) int {
+	var badRequest errBadRequest
+	switch {
+	case errors.As(err, &badRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, types.ErrPluginNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, types.ErrServerBusy):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
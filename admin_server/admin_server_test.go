@@ -0,0 +1,158 @@
+package adminserver
+
+import (
+	"autonity-oracle/types"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/phayes/freeport"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type fakeBackend struct {
+	plugins  []types.PluginInfo
+	samples  map[string]map[string][]types.Price
+	rounds   []types.RoundData
+	reports  []types.DeviationReport
+	unloaded []string
+}
+
+func (f *fakeBackend) Plugins() ([]types.PluginInfo, error) {
+	return f.plugins, nil
+}
+
+func (f *fakeBackend) Samples(name string) (map[string][]types.Price, error) {
+	s, ok := f.samples[name]
+	if !ok {
+		return nil, types.ErrPluginNotFound
+	}
+	return s, nil
+}
+
+func (f *fakeBackend) RoundState() (types.RoundState, error) {
+	return types.RoundState{Round: 10, VotePeriod: 30, SampleTS: 1700000000, SampleHeight: 300}, nil
+}
+
+func (f *fakeBackend) RecentRoundData(n int) ([]types.RoundData, error) {
+	if len(f.rounds) > n {
+		return f.rounds[:n], nil
+	}
+	return f.rounds, nil
+}
+
+func (f *fakeBackend) ShadowReports(n int) ([]types.DeviationReport, error) {
+	if len(f.reports) > n {
+		return f.reports[:n], nil
+	}
+	return f.reports, nil
+}
+
+func (f *fakeBackend) Accuracy() (types.AccuracyReport, error) {
+	return types.AccuracyReport{
+		Symbols: []types.AccuracyStats{{Name: "NTN-USD", Rounds: 10, Failed: 1, MeanDeviation: 0.01, MaxDeviation: 0.02}},
+		Plugins: []types.AccuracyStats{{Name: "template_plugin", Rounds: 9, MeanDeviation: 0.01, MaxDeviation: 0.02}},
+	}, nil
+}
+
+func (f *fakeBackend) ReloadPlugin(name string) error {
+	return types.ErrServerBusy
+}
+
+func (f *fakeBackend) UnloadPlugin(name string) error {
+	if _, ok := f.samples[name]; !ok {
+		return types.ErrPluginNotFound
+	}
+	f.unloaded = append(f.unloaded, name)
+	return nil
+}
+
+func TestAdminServer(t *testing.T) {
+	backend := &fakeBackend{
+		plugins: []types.PluginInfo{{Name: "template_plugin", Version: "v0.0.2", Protocol: types.ProtocolV2}},
+		samples: map[string]map[string][]types.Price{
+			"template_plugin": {
+				"NTN-USD": {{Timestamp: 100, Symbol: "NTN-USD", Price: decimal.RequireFromString("10.5")}},
+				"EUR-USD": {{Timestamp: 100, Symbol: "EUR-USD", Price: decimal.RequireFromString("1.08")}},
+			},
+		},
+		rounds: []types.RoundData{
+			{RoundID: 10, Salt: big.NewInt(100), Symbols: []string{"NTN-USD"}},
+			{RoundID: 9, Salt: big.NewInt(99), Symbols: []string{"NTN-USD"}},
+			{RoundID: 8, Salt: big.NewInt(88), Symbols: []string{"NTN-USD"}},
+		},
+		reports: []types.DeviationReport{
+			{Round: 9, Symbols: []types.SymbolDeviation{{Symbol: "NTN-USD", Shadow: decimal.RequireFromString("10.5"),
+				OnChain: decimal.RequireFromString("10"), Deviation: 0.05}}},
+		},
+	}
+
+	port, err := freeport.GetFreePort()
+	require.NoError(t, err)
+	as := NewAdminServer(hclog.Info, fmt.Sprintf("127.0.0.1:%d", port), backend)
+	as.Start()
+	defer as.Stop()
+
+	call := func(method, path string, result interface{}) int {
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			req, err := http.NewRequest(method, fmt.Sprintf("http://127.0.0.1:%d%s", port, path), nil)
+			require.NoError(t, err)
+			resp, err = http.DefaultClient.Do(req)
+			return err == nil
+		}, 5*time.Second, 100*time.Millisecond)
+		defer resp.Body.Close()
+		if result != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+		return resp.StatusCode
+	}
+
+	var plugins []types.PluginInfo
+	require.Equal(t, http.StatusOK, call(http.MethodGet, PluginsPath, &plugins))
+	require.Equal(t, backend.plugins[0].Name, plugins[0].Name)
+	require.Equal(t, types.ProtocolV2, plugins[0].Protocol)
+
+	var samples map[string][]types.Price
+	require.Equal(t, http.StatusOK, call(http.MethodGet, SamplesPath+"?name=template_plugin&symbol=NTN-USD", &samples))
+	require.Equal(t, 1, len(samples))
+	require.True(t, samples["NTN-USD"][0].Price.Equal(decimal.RequireFromString("10.5")))
+	require.Equal(t, http.StatusNotFound, call(http.MethodGet, SamplesPath+"?name=unknown", nil))
+	require.Equal(t, http.StatusBadRequest, call(http.MethodGet, SamplesPath, nil))
+
+	var state types.RoundState
+	require.Equal(t, http.StatusOK, call(http.MethodGet, RoundPath, &state))
+	require.Equal(t, uint64(10), state.Round)
+	require.Equal(t, uint64(300), state.SampleHeight)
+
+	var rounds []roundData
+	require.Equal(t, http.StatusOK, call(http.MethodGet, RoundDataPath+"?n=2", &rounds))
+	require.Equal(t, 2, len(rounds))
+	require.Equal(t, uint64(10), rounds[0].RoundID)
+	// the salt of the current round is not exposed before the commitment is revealed.
+	require.Nil(t, rounds[0].Salt)
+	require.Equal(t, uint64(9), rounds[1].RoundID)
+	require.Equal(t, int64(99), rounds[1].Salt.Int64())
+	require.Nil(t, rounds[1].TxHash)
+	require.Equal(t, http.StatusBadRequest, call(http.MethodGet, RoundDataPath+"?n=-1", nil))
+
+	var reports []types.DeviationReport
+	require.Equal(t, http.StatusOK, call(http.MethodGet, ShadowPath, &reports))
+	require.Equal(t, 1, len(reports))
+	require.Equal(t, 0.05, reports[0].Symbols[0].Deviation)
+	require.True(t, reports[0].Symbols[0].OnChain.Equal(decimal.RequireFromString("10")))
+
+	var accuracy types.AccuracyReport
+	require.Equal(t, http.StatusOK, call(http.MethodGet, AccuracyPath, &accuracy))
+	require.Equal(t, 1, accuracy.Symbols[0].Failed)
+	require.Equal(t, "template_plugin", accuracy.Plugins[0].Name)
+
+	require.Equal(t, http.StatusMethodNotAllowed, call(http.MethodGet, UnloadPluginPath+"?name=template_plugin", nil))
+	require.Equal(t, http.StatusOK, call(http.MethodPost, UnloadPluginPath+"?name=template_plugin", nil))
+	require.Equal(t, []string{"template_plugin"}, backend.unloaded)
+	require.Equal(t, http.StatusServiceUnavailable, call(http.MethodPost, ReloadPluginPath+"?name=template_plugin", nil))
+}
@@ -0,0 +1,248 @@
+package aggregator
+
+import (
+	"autonity-oracle/helpers"
+	"autonity-oracle/types"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+// The strategies to aggregate the samples of a symbol collected from different plugins.
+const (
+	Median      = "median"
+	VWAP        = "vwap"
+	TrimmedMean = "trimmed_mean"
+	Weighted    = "weighted"
+	Primary     = "primary"
+)
+
+var (
+	DefaultTrim   = 0.2                   // the fraction of samples dropped from each end by trimmed_mean if it is not set.
+	DefaultWeight = decimal.NewFromInt(1) // the weight of the plugins which are not listed in the weights of weighted.
+
+	ErrNoSamples       = errors.New("no samples to be aggregated")
+	ErrZeroTotalWeight = errors.New("the total weight of the samples is zero")
+)
+
+// Sample is a price of a symbol sampled by a plugin.
+type Sample struct {
+	Plugin string
+	Price  types.Price
+}
+
+// Aggregator aggregates the samples of a symbol into the price to be reported.
+type Aggregator interface {
+	Aggregate(samples []Sample) (decimal.Decimal, error)
+}
+
+// Aggregators resolves the aggregator and the outlier filter of a symbol, the default ones are applied to those
+// symbols without a strategy.
+type Aggregators struct {
+	defaultAggregator Aggregator
+	defaultFilter     *OutlierFilter
+	bySymbol          map[string]Aggregator
+	filters           map[string]*OutlierFilter
+}
+
+func NewAggregators(conf *types.AggregationConfig) (*Aggregators, error) {
+	def, err := New(conf.Default)
+	if err != nil {
+		return nil, fmt.Errorf("default aggregator: %w", err)
+	}
+
+	defFilter, err := NewOutlierFilter(conf.Outlier)
+	if err != nil {
+		return nil, fmt.Errorf("default outlier filter: %w", err)
+	}
+
+	aggregators := &Aggregators{
+		defaultAggregator: def,
+		defaultFilter:     defFilter,
+		bySymbol:          make(map[string]Aggregator),
+		filters:           make(map[string]*OutlierFilter),
+	}
+	for symbol, c := range conf.Symbols {
+		a, err := New(c)
+		if err != nil {
+			return nil, fmt.Errorf("aggregator of %s: %w", symbol, err)
+		}
+		aggregators.bySymbol[symbol] = a
+
+		if c.Outlier == nil {
+			continue
+		}
+		f, err := NewOutlierFilter(*c.Outlier)
+		if err != nil {
+			return nil, fmt.Errorf("outlier filter of %s: %w", symbol, err)
+		}
+		aggregators.filters[symbol] = f
+	}
+	return aggregators, nil
+}
+
+// Of returns the aggregator of the symbol.
+func (as *Aggregators) Of(symbol string) Aggregator {
+	if a, ok := as.bySymbol[symbol]; ok {
+		return a
+	}
+	return as.defaultAggregator
+}
+
+// FilterOf returns the outlier filter of the symbol.
+func (as *Aggregators) FilterOf(symbol string) *OutlierFilter {
+	if f, ok := as.filters[symbol]; ok {
+		return f
+	}
+	return as.defaultFilter
+}
+
+// New creates an aggregator of the strategy in the config, the median is applied if the strategy is not set.
+func New(conf types.AggregatorConfig) (Aggregator, error) {
+	switch conf.Strategy {
+	case "", Median:
+		return &medianAggregator{}, nil
+	case VWAP:
+		return &vwapAggregator{}, nil
+	case TrimmedMean:
+		trim := DefaultTrim
+		if conf.Trim != nil {
+			trim = *conf.Trim
+		}
+		if trim < 0 || trim >= 0.5 {
+			return nil, fmt.Errorf("trim %v of %s is out of range [0, 0.5)", trim, TrimmedMean)
+		}
+		return &trimmedMeanAggregator{trim: trim}, nil
+	case Weighted:
+		weights := make(map[string]decimal.Decimal)
+		for plugin, w := range conf.Weights {
+			if w < 0 {
+				return nil, fmt.Errorf("negative weight %v of plugin %s", w, plugin)
+			}
+			weights[plugin] = decimal.NewFromFloat(w)
+		}
+		return &weightedAggregator{weights: weights}, nil
+	case Primary:
+		if len(conf.Primary) == 0 {
+			return nil, fmt.Errorf("no primary plugins set for %s", Primary)
+		}
+		if conf.Fallback == Primary {
+			return nil, fmt.Errorf("the fallback of %s cannot be %s", Primary, Primary)
+		}
+		fallback, err := New(types.AggregatorConfig{Strategy: conf.Fallback, Trim: conf.Trim, Weights: conf.Weights})
+		if err != nil {
+			return nil, err
+		}
+		return &primaryAggregator{primary: conf.Primary, fallback: fallback}, nil
+	default:
+		return nil, fmt.Errorf("unknown aggregation strategy: %s", conf.Strategy)
+	}
+}
+
+// medianAggregator takes the median of the samples.
+type medianAggregator struct{}
+
+func (a *medianAggregator) Aggregate(samples []Sample) (decimal.Decimal, error) {
+	if len(samples) == 0 {
+		return decimal.Decimal{}, ErrNoSamples
+	}
+	return helpers.Median(prices(samples))
+}
+
+// vwapAggregator takes the mean of the samples weighted by their volumes, it falls back to the median if none of the
+// samples carries a volume.
+type vwapAggregator struct{}
+
+func (a *vwapAggregator) Aggregate(samples []Sample) (decimal.Decimal, error) {
+	if len(samples) == 0 {
+		return decimal.Decimal{}, ErrNoSamples
+	}
+
+	sum, totalVolume := decimal.Zero, decimal.Zero
+	for _, s := range samples {
+		if !s.Price.Volume.IsPositive() {
+			continue
+		}
+		sum = sum.Add(s.Price.Price.Mul(s.Price.Volume))
+		totalVolume = totalVolume.Add(s.Price.Volume)
+	}
+
+	if totalVolume.IsZero() {
+		return helpers.Median(prices(samples))
+	}
+	return sum.Div(totalVolume), nil
+}
+
+// trimmedMeanAggregator drops the fraction of trim samples from both ends of the sorted samples, and takes the mean
+// of the rest.
+type trimmedMeanAggregator struct {
+	trim float64
+}
+
+func (a *trimmedMeanAggregator) Aggregate(samples []Sample) (decimal.Decimal, error) {
+	if len(samples) == 0 {
+		return decimal.Decimal{}, ErrNoSamples
+	}
+
+	ps := prices(samples)
+	sort.Slice(ps, func(i, j int) bool {
+		return ps[i].LessThan(ps[j])
+	})
+
+	k := int(float64(len(ps)) * a.trim)
+	kept := ps[k : len(ps)-k]
+	return decimal.Sum(kept[0], kept[1:]...).Div(decimal.NewFromInt(int64(len(kept)))), nil
+}
+
+// weightedAggregator takes the mean of the samples weighted by the priority of the plugins.
+type weightedAggregator struct {
+	weights map[string]decimal.Decimal
+}
+
+func (a *weightedAggregator) Aggregate(samples []Sample) (decimal.Decimal, error) {
+	if len(samples) == 0 {
+		return decimal.Decimal{}, ErrNoSamples
+	}
+
+	sum, totalWeight := decimal.Zero, decimal.Zero
+	for _, s := range samples {
+		w, ok := a.weights[s.Plugin]
+		if !ok {
+			w = DefaultWeight
+		}
+		sum = sum.Add(s.Price.Price.Mul(w))
+		totalWeight = totalWeight.Add(w)
+	}
+
+	if totalWeight.IsZero() {
+		return decimal.Decimal{}, ErrZeroTotalWeight
+	}
+	return sum.Div(totalWeight), nil
+}
+
+// primaryAggregator takes the sample of the first available plugin in the priority list, the fallback strategy is
+// applied once none of them is available.
+type primaryAggregator struct {
+	primary  []string
+	fallback Aggregator
+}
+
+func (a *primaryAggregator) Aggregate(samples []Sample) (decimal.Decimal, error) {
+	for _, plugin := range a.primary {
+		for _, s := range samples {
+			if s.Plugin == plugin {
+				return s.Price.Price, nil
+			}
+		}
+	}
+	return a.fallback.Aggregate(samples)
+}
+
+func prices(samples []Sample) []decimal.Decimal {
+	ps := make([]decimal.Decimal, 0, len(samples))
+	for _, s := range samples {
+		ps = append(ps, s.Price.Price)
+	}
+	return ps
+}
@@ -0,0 +1,137 @@
+package aggregator
+
+import (
+	"autonity-oracle/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newSample(plugin, price, volume string) Sample {
+	s := Sample{Plugin: plugin, Price: types.Price{Symbol: "NTN-USD", Price: decimal.RequireFromString(price)}}
+	if volume != "" {
+		s.Price.Volume = decimal.RequireFromString(volume)
+	}
+	return s
+}
+
+func TestAggregators(t *testing.T) {
+	samples := []Sample{
+		newSample("p1", "1.0", "100"),
+		newSample("p2", "2.0", "300"),
+		newSample("p3", "3.0", ""),
+		newSample("p4", "4.0", "0"),
+		newSample("p5", "100.0", ""),
+	}
+
+	t.Run("median", func(t *testing.T) {
+		a, err := New(types.AggregatorConfig{})
+		require.NoError(t, err)
+		p, err := a.Aggregate(samples)
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("3.0")))
+
+		_, err = a.Aggregate(nil)
+		require.ErrorIs(t, err, ErrNoSamples)
+	})
+
+	t.Run("volume weighted mean", func(t *testing.T) {
+		a, err := New(types.AggregatorConfig{Strategy: VWAP})
+		require.NoError(t, err)
+		p, err := a.Aggregate(samples)
+		require.NoError(t, err)
+		// (1*100 + 2*300) / 400, the samples without volume are ignored.
+		require.True(t, p.Equal(decimal.RequireFromString("1.75")))
+
+		// fall back to median without any volume.
+		p, err = a.Aggregate(samples[2:])
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("4.0")))
+	})
+
+	t.Run("trimmed mean", func(t *testing.T) {
+		trim := 0.2
+		a, err := New(types.AggregatorConfig{Strategy: TrimmedMean, Trim: &trim})
+		require.NoError(t, err)
+		p, err := a.Aggregate(samples)
+		require.NoError(t, err)
+		// 1.0 and 100.0 are trimmed.
+		require.True(t, p.Equal(decimal.RequireFromString("3.0")))
+
+		// nothing is trimmed from 2 samples.
+		p, err = a.Aggregate(samples[:2])
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("1.5")))
+
+		// the default trim is taken if it is not set.
+		a, err = New(types.AggregatorConfig{Strategy: TrimmedMean})
+		require.NoError(t, err)
+		p, err = a.Aggregate(samples)
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("3.0")))
+
+		// nothing is trimmed with a zero trim.
+		trim = 0
+		a, err = New(types.AggregatorConfig{Strategy: TrimmedMean, Trim: &trim})
+		require.NoError(t, err)
+		p, err = a.Aggregate(samples)
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("22.0")))
+
+		trim = 0.5
+		_, err = New(types.AggregatorConfig{Strategy: TrimmedMean, Trim: &trim})
+		require.Error(t, err)
+	})
+
+	t.Run("weighted by plugin priority", func(t *testing.T) {
+		a, err := New(types.AggregatorConfig{Strategy: Weighted, Weights: map[string]float64{"p1": 3, "p2": 1, "p5": 0}})
+		require.NoError(t, err)
+		p, err := a.Aggregate(samples)
+		require.NoError(t, err)
+		// (1*3 + 2*1 + 3*1 + 4*1 + 100*0) / 6
+		require.True(t, p.Equal(decimal.RequireFromString("2")))
+
+		_, err = a.Aggregate(samples[4:])
+		require.ErrorIs(t, err, ErrZeroTotalWeight)
+
+		_, err = New(types.AggregatorConfig{Strategy: Weighted, Weights: map[string]float64{"p1": -1}})
+		require.Error(t, err)
+	})
+
+	t.Run("primary with fallback", func(t *testing.T) {
+		a, err := New(types.AggregatorConfig{Strategy: Primary, Primary: []string{"p9", "p4", "p1"}, Fallback: VWAP})
+		require.NoError(t, err)
+		p, err := a.Aggregate(samples)
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("4.0")))
+
+		// none of the primary plugins is available.
+		p, err = a.Aggregate(samples[1:3])
+		require.NoError(t, err)
+		require.True(t, p.Equal(decimal.RequireFromString("2.0")))
+
+		_, err = New(types.AggregatorConfig{Strategy: Primary})
+		require.Error(t, err)
+		_, err = New(types.AggregatorConfig{Strategy: Primary, Primary: []string{"p1"}, Fallback: Primary})
+		require.Error(t, err)
+	})
+
+	t.Run("aggregators by symbol", func(t *testing.T) {
+		as, err := NewAggregators(&types.AggregationConfig{
+			Outlier: types.OutlierConfig{MaxDeviation: 0.05},
+			Symbols: map[string]types.AggregatorConfig{
+				"NTN-USD": {Strategy: VWAP, Outlier: &types.OutlierConfig{MaxJump: 0.1}},
+			},
+		})
+		require.NoError(t, err)
+		require.IsType(t, &vwapAggregator{}, as.Of("NTN-USD"))
+		require.IsType(t, &medianAggregator{}, as.Of("EUR-USD"))
+		require.True(t, as.FilterOf("NTN-USD").ChecksJump())
+		require.False(t, as.FilterOf("EUR-USD").ChecksJump())
+
+		_, err = NewAggregators(&types.AggregationConfig{
+			Symbols: map[string]types.AggregatorConfig{"NTN-USD": {Strategy: "mode"}},
+		})
+		require.Error(t, err)
+	})
+}
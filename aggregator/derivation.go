@@ -0,0 +1,233 @@
+package aggregator
+
+import (
+	"autonity-oracle/types"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+var (
+	ErrNoDerivation = errors.New("no derivation rule")
+	ErrZeroDivisor  = errors.New("the divisor of the derivation is zero")
+)
+
+// Resolver returns the aggregated price of a symbol.
+type Resolver func(symbol string) (decimal.Decimal, error)
+
+// Derivation is the price of a symbol derived from the prices of other symbols.
+type Derivation struct {
+	Price  decimal.Decimal
+	Inputs []string // the symbols whose prices are used by the derivation.
+}
+
+// operand of a rule is either a symbol or a constant, the price of a symbol can be inverted.
+type operand struct {
+	symbol   string
+	inverted bool
+	constant decimal.Decimal
+}
+
+func parseOperand(s string) (operand, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return operand{}, fmt.Errorf("missing operand")
+	}
+	if c, err := decimal.NewFromString(s); err == nil {
+		return operand{constant: c}, nil
+	}
+	return operand{symbol: s}, nil
+}
+
+func (o operand) resolve(resolve Resolver) (decimal.Decimal, error) {
+	if o.symbol == "" {
+		return o.constant, nil
+	}
+	price, err := resolve(o.symbol)
+	if err != nil || !o.inverted {
+		return price, err
+	}
+	if price.IsZero() {
+		return decimal.Decimal{}, ErrZeroDivisor
+	}
+	return decimal.NewFromInt(1).Div(price), nil
+}
+
+// Rule derives the price of a symbol by multiplying or dividing two operands, e.g. "NTN-USD / ATN-USD" or "1 / EUR-USD".
+type Rule struct {
+	left, right operand
+	divide      bool
+}
+
+func ParseRule(expr string) (*Rule, error) {
+	i := strings.IndexAny(expr, "*/")
+	if i < 0 || strings.LastIndexAny(expr, "*/") != i {
+		return nil, fmt.Errorf("rule %q must be in the form of <operand> * <operand> or <operand> / <operand>", expr)
+	}
+
+	left, err := parseOperand(expr[:i])
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", expr, err)
+	}
+	right, err := parseOperand(expr[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", expr, err)
+	}
+	if left.symbol == "" && right.symbol == "" {
+		return nil, fmt.Errorf("rule %q does not refer to any symbol", expr)
+	}
+	if expr[i] == '/' && right.symbol == "" && right.constant.IsZero() {
+		return nil, fmt.Errorf("rule %q: %w", expr, ErrZeroDivisor)
+	}
+	return &Rule{left: left, right: right, divide: expr[i] == '/'}, nil
+}
+
+// Inputs returns the symbols referred by the rule.
+func (r *Rule) Inputs() []string {
+	var inputs []string
+	for _, o := range []operand{r.left, r.right} {
+		if o.symbol != "" {
+			inputs = append(inputs, o.symbol)
+		}
+	}
+	return inputs
+}
+
+func (r *Rule) Derive(resolve Resolver) (*Derivation, error) {
+	left, err := r.left.resolve(resolve)
+	if err != nil {
+		return nil, err
+	}
+	right, err := r.right.resolve(resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Derivation{Inputs: r.Inputs()}
+	if !r.divide {
+		d.Price = left.Mul(right)
+		return d, nil
+	}
+	if right.IsZero() {
+		return nil, ErrZeroDivisor
+	}
+	d.Price = left.Div(right)
+	return d, nil
+}
+
+// Deriver derives the prices of the symbols which are not provided by any plugin. The rule of a symbol is tried first,
+// then the inversion of the reversed pair, and then the triangulation through the pivot currencies.
+type Deriver struct {
+	rules  map[string]*Rule
+	invert bool
+	pivots []string
+}
+
+func NewDeriver(conf types.DerivationConfig) (*Deriver, error) {
+	d := &Deriver{rules: make(map[string]*Rule), invert: conf.Invert, pivots: conf.Pivots}
+	for symbol, expr := range conf.Rules {
+		r, err := ParseRule(expr)
+		if err != nil {
+			return nil, fmt.Errorf("derivation of %s: %w", symbol, err)
+		}
+		for _, input := range r.Inputs() {
+			if input == symbol {
+				return nil, fmt.Errorf("derivation of %s refers to itself", symbol)
+			}
+		}
+		d.rules[symbol] = r
+	}
+	for _, p := range conf.Pivots {
+		if p == "" || strings.Contains(p, "-") {
+			return nil, fmt.Errorf("invalid pivot currency %q", p)
+		}
+	}
+	return d, nil
+}
+
+// Derive returns the price of the symbol derived from the prices resolved by the resolver, the first applicable rule
+// wins. A nil deriver does not derive any price.
+func (d *Deriver) Derive(symbol string, resolve Resolver) (*Derivation, error) {
+	if d == nil {
+		return nil, ErrNoDerivation
+	}
+
+	var err = ErrNoDerivation
+	for _, r := range d.candidates(symbol) {
+		var derived *Derivation
+		if derived, err = r.Derive(resolve); err == nil {
+			return derived, nil
+		}
+	}
+	return nil, err
+}
+
+// Extend returns the symbols with the inputs of their derivations, thus the inputs are sampled from the plugins too.
+func (d *Deriver) Extend(symbols []string) []string {
+	if d == nil {
+		return symbols
+	}
+
+	result := make([]string, 0, len(symbols))
+	seen := make(map[string]struct{})
+	add := func(s string) {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			result = append(result, s)
+		}
+	}
+	for _, s := range symbols {
+		add(s)
+	}
+	for _, s := range symbols {
+		for _, r := range d.candidates(s) {
+			for _, input := range r.Inputs() {
+				add(input)
+			}
+		}
+	}
+	return result
+}
+
+// candidates returns the rules to derive the symbol in the order of preference.
+func (d *Deriver) candidates(symbol string) []*Rule {
+	var rules []*Rule
+	if r, ok := d.rules[symbol]; ok {
+		rules = append(rules, r)
+	}
+
+	base, quote, ok := splitPair(symbol)
+	if !ok {
+		return rules
+	}
+	one := operand{constant: decimal.NewFromInt(1)}
+	if d.invert {
+		rules = append(rules, &Rule{left: one, right: operand{symbol: quote + "-" + base}, divide: true})
+	}
+	for _, p := range d.pivots {
+		if p == base || p == quote {
+			continue
+		}
+		// X-Y = X-P / Y-P, where X-P can be taken as 1 / P-X, and Y-P as 1 / P-Y.
+		for _, x := range pivotLegs(base, p) {
+			for _, y := range pivotLegs(quote, p) {
+				rules = append(rules, &Rule{left: x, right: y, divide: true})
+			}
+		}
+	}
+	return rules
+}
+
+// pivotLegs returns the operands of the price of currency in the pivot currency, directly or by the inversion.
+func pivotLegs(currency, pivot string) []operand {
+	return []operand{{symbol: currency + "-" + pivot}, {symbol: pivot + "-" + currency, inverted: true}}
+}
+
+func splitPair(symbol string) (string, string, bool) {
+	parts := strings.Split(symbol, "-")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,105 @@
+package aggregator
+
+import (
+	"autonity-oracle/types"
+	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDeriver(t *testing.T) {
+	prices := map[string]string{
+		"NTN-USD": "10",
+		"ATN-USD": "4",
+		"EUR-USD": "1.25",
+		"USD-JPY": "100",
+	}
+	resolve := func(symbol string) (decimal.Decimal, error) {
+		if p, ok := prices[symbol]; ok {
+			return decimal.RequireFromString(p), nil
+		}
+		return decimal.Decimal{}, errors.New("no price")
+	}
+
+	t.Run("parse rules", func(t *testing.T) {
+		r, err := ParseRule("NTN-USD / ATN-USD")
+		require.NoError(t, err)
+		require.Equal(t, []string{"NTN-USD", "ATN-USD"}, r.Inputs())
+
+		r, err = ParseRule("1/EUR-USD")
+		require.NoError(t, err)
+		require.Equal(t, []string{"EUR-USD"}, r.Inputs())
+
+		for _, expr := range []string{"NTN-USD", "NTN-USD / ATN-USD * 2", "2 * 3", "NTN-USD / 0", "/ ATN-USD"} {
+			_, err = ParseRule(expr)
+			require.Error(t, err, expr)
+		}
+
+		_, err = NewDeriver(types.DerivationConfig{Rules: map[string]string{"NTN-ATN": "NTN-ATN * 1"}})
+		require.Error(t, err)
+		_, err = NewDeriver(types.DerivationConfig{Pivots: []string{"USD-EUR"}})
+		require.Error(t, err)
+	})
+
+	t.Run("derive by the rules", func(t *testing.T) {
+		d, err := NewDeriver(types.DerivationConfig{Rules: map[string]string{
+			"NTN-ATN": "NTN-USD / ATN-USD",
+			"USD-EUR": "1 / EUR-USD",
+			"NTN-EUR": "NTN-USD * USD-EUR",
+		}})
+		require.NoError(t, err)
+
+		derived, err := d.Derive("NTN-ATN", resolve)
+		require.NoError(t, err)
+		require.True(t, derived.Price.Equal(decimal.RequireFromString("2.5")))
+		require.Equal(t, []string{"NTN-USD", "ATN-USD"}, derived.Inputs)
+
+		derived, err = d.Derive("USD-EUR", resolve)
+		require.NoError(t, err)
+		require.True(t, derived.Price.Equal(decimal.RequireFromString("0.8")))
+
+		// the inputs of a rule are not derived, thus USD-EUR is missing.
+		_, err = d.Derive("NTN-EUR", resolve)
+		require.Error(t, err)
+
+		_, err = d.Derive("ATN-EUR", resolve)
+		require.ErrorIs(t, err, ErrNoDerivation)
+	})
+
+	t.Run("derive by the inversion and the triangulation", func(t *testing.T) {
+		d, err := NewDeriver(types.DerivationConfig{Invert: true, Pivots: []string{"USD"}})
+		require.NoError(t, err)
+
+		derived, err := d.Derive("USD-NTN", resolve)
+		require.NoError(t, err)
+		require.True(t, derived.Price.Equal(decimal.RequireFromString("0.1")))
+		require.Equal(t, []string{"NTN-USD"}, derived.Inputs)
+
+		// NTN-EUR = NTN-USD / EUR-USD
+		derived, err = d.Derive("NTN-EUR", resolve)
+		require.NoError(t, err)
+		require.True(t, derived.Price.Equal(decimal.RequireFromString("8")))
+		require.Equal(t, []string{"NTN-USD", "EUR-USD"}, derived.Inputs)
+
+		// NTN-JPY = NTN-USD / (1 / USD-JPY)
+		derived, err = d.Derive("NTN-JPY", resolve)
+		require.NoError(t, err)
+		require.True(t, derived.Price.Equal(decimal.RequireFromString("1000")))
+		require.Equal(t, []string{"NTN-USD", "USD-JPY"}, derived.Inputs)
+
+		_, err = d.Derive("NTN-GBP", resolve)
+		require.Error(t, err)
+	})
+
+	t.Run("extend the symbols with the inputs of the derivations", func(t *testing.T) {
+		d, err := NewDeriver(types.DerivationConfig{Rules: map[string]string{"NTN-ATN": "NTN-USD / ATN-USD"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"NTN-USD", "NTN-ATN", "ATN-USD"}, d.Extend([]string{"NTN-USD", "NTN-ATN"}))
+
+		var nilDeriver *Deriver
+		require.Equal(t, []string{"NTN-ATN"}, nilDeriver.Extend([]string{"NTN-ATN"}))
+		_, err = nilDeriver.Derive("NTN-ATN", resolve)
+		require.ErrorIs(t, err, ErrNoDerivation)
+	})
+}
@@ -0,0 +1,127 @@
+package aggregator
+
+import (
+	"autonity-oracle/helpers"
+	"autonity-oracle/types"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// The reasons of the exclusion of an outlier sample.
+const (
+	ReasonDeviation = "deviation from the median of the plugins"
+	ReasonMAD       = "MAD distance from the median of the plugins"
+	ReasonJump      = "jump from the last on-chain price"
+)
+
+// MinPeerSamples is the minimum number of samples to tell the outlier by the consensus of the other plugins, with 2
+// samples it cannot tell which one is wrong.
+var MinPeerSamples = 3
+
+// Exclusion is a sample dropped by the outlier filter with the reason, the reference price it was compared with, and
+// the distance from the reference, which is relative for deviation and jump, and in MADs for MAD distance.
+type Exclusion struct {
+	Sample    Sample
+	Reason    string
+	Reference decimal.Decimal
+	Distance  decimal.Decimal
+}
+
+// OutlierFilter drops those samples which are too far from the consensus of the plugins, or which jump too far
+// from the last on-chain price of the symbol.
+type OutlierFilter struct {
+	maxDeviation decimal.Decimal
+	madThreshold decimal.Decimal
+	maxJump      decimal.Decimal
+}
+
+func NewOutlierFilter(conf types.OutlierConfig) (*OutlierFilter, error) {
+	if conf.MaxDeviation < 0 || conf.MADThreshold < 0 || conf.MaxJump < 0 {
+		return nil, fmt.Errorf("negative outlier threshold: %+v", conf)
+	}
+	return &OutlierFilter{
+		maxDeviation: decimal.NewFromFloat(conf.MaxDeviation),
+		madThreshold: decimal.NewFromFloat(conf.MADThreshold),
+		maxJump:      decimal.NewFromFloat(conf.MaxJump),
+	}, nil
+}
+
+// ChecksJump tells if the last on-chain price is required by the filter.
+func (f *OutlierFilter) ChecksJump() bool {
+	return f.maxJump.IsPositive()
+}
+
+// Filter returns the samples which pass the checks and those excluded ones. The peer checks are skipped if they would
+// exclude the majority of the samples, since there is no consensus to tell the outlier. The jump check is skipped if
+// the last price is nil, and it does not exclude anything if all the samples jump, since that is a move of the market.
+func (f *OutlierFilter) Filter(samples []Sample, last *decimal.Decimal) ([]Sample, []Exclusion) {
+	kept, excluded := f.filterByPeers(samples)
+	if len(excluded) > 0 && len(kept) <= len(samples)/2 {
+		kept, excluded = samples, nil
+	}
+
+	if !f.ChecksJump() || last == nil || !last.IsPositive() {
+		return kept, excluded
+	}
+
+	var passed []Sample
+	var jumped []Exclusion
+	for _, s := range kept {
+		distance := s.Price.Price.Sub(*last).Abs().Div(*last)
+		if distance.GreaterThan(f.maxJump) {
+			jumped = append(jumped, Exclusion{Sample: s, Reason: ReasonJump, Reference: *last, Distance: distance})
+			continue
+		}
+		passed = append(passed, s)
+	}
+
+	if len(passed) == 0 {
+		return kept, excluded
+	}
+	return passed, append(excluded, jumped...)
+}
+
+// filterByPeers compares each sample with the consensus of the plugins, that is the median of all the samples including
+// the one under test. The median is not moved by a single off sample, while the median of the others would be the mean
+// of the remaining 2 samples with 3 plugins, in which the off sample drags the reference away from the good ones.
+func (f *OutlierFilter) filterByPeers(samples []Sample) ([]Sample, []Exclusion) {
+	if len(samples) < MinPeerSamples || (!f.maxDeviation.IsPositive() && !f.madThreshold.IsPositive()) {
+		return samples, nil
+	}
+
+	median, err := helpers.Median(prices(samples))
+	if err != nil {
+		return samples, nil
+	}
+
+	// the MAD is zero once the majority of the plugins agree on the price, the MAD distance is meaningless then.
+	deviations := make([]decimal.Decimal, 0, len(samples))
+	for _, s := range samples {
+		deviations = append(deviations, s.Price.Price.Sub(median).Abs())
+	}
+	mad, err := helpers.Median(deviations)
+	if err != nil {
+		return samples, nil
+	}
+
+	var kept []Sample
+	var excluded []Exclusion
+	for _, s := range samples {
+		diff := s.Price.Price.Sub(median).Abs()
+		if f.maxDeviation.IsPositive() && median.IsPositive() {
+			if distance := diff.Div(median); distance.GreaterThan(f.maxDeviation) {
+				excluded = append(excluded, Exclusion{Sample: s, Reason: ReasonDeviation, Reference: median, Distance: distance})
+				continue
+			}
+		}
+
+		if f.madThreshold.IsPositive() && !mad.IsZero() {
+			if distance := diff.Div(mad); distance.GreaterThan(f.madThreshold) {
+				excluded = append(excluded, Exclusion{Sample: s, Reason: ReasonMAD, Reference: median, Distance: distance})
+				continue
+			}
+		}
+		kept = append(kept, s)
+	}
+	return kept, excluded
+}
@@ -0,0 +1,89 @@
+package aggregator
+
+import (
+	"autonity-oracle/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOutlierFilter(t *testing.T) {
+	samples := []Sample{
+		newSample("p1", "1.00", ""),
+		newSample("p2", "1.01", ""),
+		newSample("p3", "0.99", ""),
+		newSample("p4", "1.00", ""),
+		newSample("currencylayer", "0.50", ""),
+	}
+
+	t.Run("deviation from the median of the plugins", func(t *testing.T) {
+		f, err := NewOutlierFilter(types.OutlierConfig{MaxDeviation: 0.05})
+		require.NoError(t, err)
+		kept, excluded := f.Filter(samples, nil)
+		require.Equal(t, 4, len(kept))
+		require.Equal(t, 1, len(excluded))
+		require.Equal(t, "currencylayer", excluded[0].Sample.Plugin)
+		require.Equal(t, ReasonDeviation, excluded[0].Reason)
+		require.True(t, excluded[0].Reference.Equal(decimal.RequireFromString("1.00")))
+		require.True(t, excluded[0].Distance.Equal(decimal.RequireFromString("0.5")))
+	})
+
+	t.Run("MAD distance from the median of the plugins", func(t *testing.T) {
+		f, err := NewOutlierFilter(types.OutlierConfig{MADThreshold: 5})
+		require.NoError(t, err)
+		kept, excluded := f.Filter(samples, nil)
+		require.Equal(t, 4, len(kept))
+		require.Equal(t, 1, len(excluded))
+		require.Equal(t, "currencylayer", excluded[0].Sample.Plugin)
+		require.Equal(t, ReasonMAD, excluded[0].Reason)
+	})
+
+	t.Run("no peer check without enough samples or without consensus", func(t *testing.T) {
+		f, err := NewOutlierFilter(types.OutlierConfig{MaxDeviation: 0.05})
+		require.NoError(t, err)
+		kept, excluded := f.Filter(samples[3:], nil)
+		require.Equal(t, 2, len(kept))
+		require.Equal(t, 0, len(excluded))
+
+		scattered := []Sample{newSample("p1", "1", ""), newSample("p2", "2", ""), newSample("p3", "3", "")}
+		kept, excluded = f.Filter(scattered, nil)
+		require.Equal(t, 3, len(kept))
+		require.Equal(t, 0, len(excluded))
+	})
+
+	t.Run("jump from the last on-chain price", func(t *testing.T) {
+		f, err := NewOutlierFilter(types.OutlierConfig{MaxJump: 0.1})
+		require.NoError(t, err)
+		require.True(t, f.ChecksJump())
+
+		last := decimal.RequireFromString("1.0")
+		kept, excluded := f.Filter(samples[3:], &last)
+		require.Equal(t, 1, len(kept))
+		require.Equal(t, "p4", kept[0].Plugin)
+		require.Equal(t, 1, len(excluded))
+		require.Equal(t, ReasonJump, excluded[0].Reason)
+
+		// all the samples jump, it is a move of the market.
+		last = decimal.RequireFromString("2.0")
+		kept, excluded = f.Filter(samples[3:], &last)
+		require.Equal(t, 2, len(kept))
+		require.Equal(t, 0, len(excluded))
+
+		// no last price on-chain.
+		kept, excluded = f.Filter(samples[3:], nil)
+		require.Equal(t, 2, len(kept))
+		require.Equal(t, 0, len(excluded))
+	})
+
+	t.Run("disabled filter", func(t *testing.T) {
+		f, err := NewOutlierFilter(types.OutlierConfig{})
+		require.NoError(t, err)
+		require.False(t, f.ChecksJump())
+		kept, excluded := f.Filter(samples, nil)
+		require.Equal(t, len(samples), len(kept))
+		require.Equal(t, 0, len(excluded))
+
+		_, err = NewOutlierFilter(types.OutlierConfig{MaxJump: -1})
+		require.Error(t, err)
+	})
+}
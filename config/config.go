@@ -1,56 +1,140 @@
 package config
 
 import (
+	feestrategy "autonity-oracle/fee_strategy"
 	"autonity-oracle/helpers"
+	"autonity-oracle/signer"
 	"autonity-oracle/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/hashicorp/go-hclog"
 	"github.com/namsral/flag"
 	"gopkg.in/yaml.v2"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 var (
-	DefaultLogVerbosity   = 3 // 0: NoLevel, 1: Trace, 2:Debug, 3: Info, 4: Warn, 5: Error
-	DefaultGasTipCap      = uint64(1)
-	DefaultAutonityWSUrl  = "ws://127.0.0.1:8546"
-	DefaultKeyFile        = "./UTC--2023-02-27T09-10-19.592765887Z--b749d3d83376276ab4ddef2d9300fb5ce70ebafe"
-	DefaultKeyPassword    = "123"
-	DefaultPluginDir      = "./plugins"
-	DefaultPluginConfFile = "./plugins-conf.yml"
-	DefaultOracleConfFile = ""
-	DefaultSymbols        = []string{"AUD-USD", "CAD-USD", "EUR-USD", "GBP-USD", "JPY-USD", "SEK-USD", "ATN-USD", "NTN-USD", "NTN-ATN"}
+	DefaultLogVerbosity    = 3 // 0: NoLevel, 1: Trace, 2:Debug, 3: Info, 4: Warn, 5: Error
+	DefaultGasStrategy     = feestrategy.Static
+	DefaultGasTipCap       = uint64(1)
+	DefaultGasTipMax       = uint64(0)
+	DefaultGasFeeCapMax    = uint64(0)
+	DefaultGasLimitMax     = uint64(3000000)
+	DefaultAutonityWSUrl   = "ws://127.0.0.1:8546"
+	DefaultKeyFile         = "./UTC--2023-02-27T09-10-19.592765887Z--b749d3d83376276ab4ddef2d9300fb5ce70ebafe"
+	DefaultKeyPassword     = "123"
+	DefaultSigner          = signer.Keystore
+	DefaultSignerURL       = ""
+	DefaultSignerAddress   = ""
+	DefaultPluginDir       = "./plugins"
+	DefaultPluginConfFile  = "./plugins-conf.yml"
+	DefaultPluginVerify    = false
+	DefaultOracleConfFile  = ""
+	DefaultAggregationConf = ""
+	DefaultDataDir         = "./data"
+	DefaultMetrics         = false
+	DefaultMetricsAddr     = "127.0.0.1:9101"
+	DefaultAdmin           = false
+	DefaultAdminAddr       = "127.0.0.1:9102"
+	DefaultDryRun          = false
+	DefaultBalanceAlert    = uint64(2000000000000) // 2000 Gwei, 0.000002 Ether
+	DefaultBalanceRunway   = uint64(100)
+	DefaultAlertWebhook    = ""
+	DefaultAlertExec       = ""
+	DefaultSymbols         = []string{"AUD-USD", "CAD-USD", "EUR-USD", "GBP-USD", "JPY-USD", "SEK-USD", "ATN-USD", "NTN-USD", "NTN-ATN"}
 )
 
 const Version = "v0.1.6"
 const UsageOracleKey = "Set the oracle server key file path."
 const UsagePluginConf = "Set the plugin's configuration file path."
+const UsagePluginVerify = "Refuse to start the plugins without a SHA-256 checksum set in the plugin's configuration file."
+const UsageAggregationConf = "Set the aggregation strategies' configuration file path, the median is applied to all symbols if it is not set."
 const UsageOracleConf = "Set the oracle server configuration file path."
 const UsagePluginDir = "Set the directory path of the data plugins."
 const UsageOracleKeyPassword = "Set the password to decrypt oracle server key file."
-const UsageGasTipCap = "Set the gas priority fee cap to issue the oracle data report transactions."
-const UsageWSUrl = "Set the WS-RPC server listening interface and port of the connected Autonity Client node."
+const UsageSigner = "Set the signer of the oracle server account, available signers are: keystore, clef and rpc."
+const UsageSignerURL = "Set the IPC path or the HTTP URL of the clef or the rpc signer."
+const UsageSignerAddress = "Set the oracle server account address managed by the clef or the rpc signer."
+const UsageGasStrategy = "Set the gas fee strategy of the oracle data report transactions, available strategies are: static and dynamic."
+const UsageGasTipCap = "Set the gas priority fee cap to issue the oracle data report transactions, it is the floor of the tip with the dynamic strategy."
+const UsageGasTipMax = "Set the ceiling of the gas priority fee cap with the dynamic strategy, 0 is no ceiling."
+const UsageGasFeeCapMax = "Set the ceiling of the gas fee cap with the dynamic strategy, 0 is no ceiling."
+const UsageGasLimitMax = "Set the gas limit of the oracle data report transactions, it is the ceiling of the estimated gas with the dynamic strategy."
+const UsageWSUrl = "Set the WS-RPC server listening interface and port of the connected Autonity Client node, multiple endpoints separated by commas are failed over by their health."
+const UsageDataDir = "Set the directory path to persist the round data of the oracle server across restarts."
+const UsageMetrics = "Enable the HTTP listener to expose the oracle server metrics in Prometheus format."
+const UsageMetricsAddr = "Set the listening interface and port of the metrics HTTP listener."
+const UsageAdmin = "Enable the local HTTP listener of the admin API to inspect the plugins, samples and rounds of the oracle server."
+const UsageAdminAddr = "Set the listening interface and port of the admin API HTTP listener, keep it on a local interface."
+const UsageDryRun = "Compute the votes without submitting them, and report their deviations from the on-chain prices."
+const UsageBalanceAlert = "Set the balance of the oracle server account in wei, below which an alert is sent."
+const UsageBalanceRunway = "Set the number of the rounds the oracle server account can still vote in, below which an alert is sent."
+const UsageAlertWebhook = "Set the URLs to post the events to in JSON, multiple URLs are separated by commas."
+const UsageAlertExec = "Set the executables to run on the events, the event is passed in JSON on the stdin, multiple paths are separated by commas."
 const UsageLogLevel = "Set the logging level, available levels are:  0: NoLevel, 1: Trace, 2:Debug, 3: Info, 4: Warn, 5: Error"
 
 func MakeConfig() *types.OracleServiceConfig {
 	var logLevel int
 	var keyFile string
+	var gasStrategy string
 	var gasTipCap uint64
+	var gasTipMax uint64
+	var gasFeeCapMax uint64
+	var gasLimitMax uint64
 	var pluginDir string
 	var keyPassword string
+	var signerType string
+	var signerURL string
+	var signerAddress string
 	var autonityWSUrl string
 	var pluginConfFile string
+	var pluginVerify bool
 	var oracleConfFile string
+	var aggregationConfFile string
+	var dataDir string
+	var metricsEnabled bool
+	var metricsAddr string
+	var adminEnabled bool
+	var adminAddr string
+	var dryRun bool
+	var balanceAlert uint64
+	var balanceRunway uint64
+	var alertWebhook string
+	var alertExec string
 
+	flag.StringVar(&gasStrategy, "gas.strategy", DefaultGasStrategy, UsageGasStrategy)
 	flag.Uint64Var(&gasTipCap, "tip", DefaultGasTipCap, UsageGasTipCap)
+	flag.Uint64Var(&gasTipMax, "tip.max", DefaultGasTipMax, UsageGasTipMax)
+	flag.Uint64Var(&gasFeeCapMax, "fee.cap.max", DefaultGasFeeCapMax, UsageGasFeeCapMax)
+	flag.Uint64Var(&gasLimitMax, "gas.limit.max", DefaultGasLimitMax, UsageGasLimitMax)
 	flag.StringVar(&keyFile, "key.file", DefaultKeyFile, UsageOracleKey)
 	flag.IntVar(&logLevel, "log.level", DefaultLogVerbosity, UsageLogLevel)
 	flag.StringVar(&autonityWSUrl, "ws", DefaultAutonityWSUrl, UsageWSUrl)
 	flag.StringVar(&pluginDir, "plugin.dir", DefaultPluginDir, UsagePluginDir)
 	flag.StringVar(&pluginConfFile, "plugin.conf", DefaultPluginConfFile, UsagePluginConf)
+	flag.BoolVar(&pluginVerify, "plugin.verify", DefaultPluginVerify, UsagePluginVerify)
+	flag.StringVar(&aggregationConfFile, "aggregation.conf", DefaultAggregationConf, UsageAggregationConf)
+	flag.StringVar(&dataDir, "data.dir", DefaultDataDir, UsageDataDir)
+	flag.BoolVar(&metricsEnabled, "metrics", DefaultMetrics, UsageMetrics)
+	flag.StringVar(&metricsAddr, "metrics.addr", DefaultMetricsAddr, UsageMetricsAddr)
+	flag.BoolVar(&adminEnabled, "admin", DefaultAdmin, UsageAdmin)
+	flag.StringVar(&adminAddr, "admin.addr", DefaultAdminAddr, UsageAdminAddr)
+	flag.BoolVar(&dryRun, "dry.run", DefaultDryRun, UsageDryRun)
+	flag.Uint64Var(&balanceAlert, "balance.alert", DefaultBalanceAlert, UsageBalanceAlert)
+	flag.Uint64Var(&balanceRunway, "balance.runway", DefaultBalanceRunway, UsageBalanceRunway)
+	flag.StringVar(&alertWebhook, "alert.webhook", DefaultAlertWebhook, UsageAlertWebhook)
+	flag.StringVar(&alertExec, "alert.exec", DefaultAlertExec, UsageAlertExec)
 	flag.StringVar(&keyPassword, "key.password", DefaultKeyPassword, UsageOracleKeyPassword)
+	flag.StringVar(&signerType, "signer", DefaultSigner, UsageSigner)
+	flag.StringVar(&signerURL, "signer.url", DefaultSignerURL, UsageSignerURL)
+	flag.StringVar(&signerAddress, "signer.address", DefaultSignerAddress, UsageSignerAddress)
 	flag.StringVar(&oracleConfFile, flag.DefaultConfigFlagname, DefaultOracleConfFile, UsageOracleConf)
 
 	flag.Parse()
@@ -89,6 +173,18 @@ func MakeConfig() *types.OracleServiceConfig {
 		keyPassword = password
 	}
 
+	if s, presented := os.LookupEnv(types.EnvSigner); presented && signerType == DefaultSigner {
+		signerType = s
+	}
+
+	if url, presented := os.LookupEnv(types.EnvSignerURL); presented && signerURL == DefaultSignerURL {
+		signerURL = url
+	}
+
+	if addr, presented := os.LookupEnv(types.EnvSignerAddress); presented && signerAddress == DefaultSignerAddress {
+		signerAddress = addr
+	}
+
 	if ws, presented := os.LookupEnv(types.EnvWS); presented && autonityWSUrl == DefaultAutonityWSUrl {
 		autonityWSUrl = ws
 	}
@@ -97,6 +193,90 @@ func MakeConfig() *types.OracleServiceConfig {
 		pluginConfFile = pluginConf
 	}
 
+	if verify, presented := os.LookupEnv(types.EnvPluginVerify); presented && pluginVerify == DefaultPluginVerify {
+		v, err := strconv.ParseBool(verify)
+		if err != nil {
+			log.Printf("wrong value configed in $PLUGIN_VERIFY")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		pluginVerify = v
+	}
+
+	if aggConf, presented := os.LookupEnv(types.EnvAggregationConf); presented && aggregationConfFile == DefaultAggregationConf {
+		aggregationConfFile = aggConf
+	}
+
+	if dir, presented := os.LookupEnv(types.EnvDataDIR); presented && dataDir == DefaultDataDir {
+		dataDir = dir
+	}
+
+	if enabled, presented := os.LookupEnv(types.EnvMetrics); presented && metricsEnabled == DefaultMetrics {
+		m, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Printf("wrong value configed in $METRICS")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		metricsEnabled = m
+	}
+
+	if addr, presented := os.LookupEnv(types.EnvMetricsAddr); presented && metricsAddr == DefaultMetricsAddr {
+		metricsAddr = addr
+	}
+
+	if enabled, presented := os.LookupEnv(types.EnvAdmin); presented && adminEnabled == DefaultAdmin {
+		a, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Printf("wrong value configed in $ADMIN")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		adminEnabled = a
+	}
+
+	if addr, presented := os.LookupEnv(types.EnvAdminAddr); presented && adminAddr == DefaultAdminAddr {
+		adminAddr = addr
+	}
+
+	if enabled, presented := os.LookupEnv(types.EnvDryRun); presented && dryRun == DefaultDryRun {
+		d, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Printf("wrong value configed in $DRY_RUN")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		dryRun = d
+	}
+
+	if alert, presented := os.LookupEnv(types.EnvBalanceAlert); presented && balanceAlert == DefaultBalanceAlert {
+		b, err := strconv.ParseUint(alert, 0, 64)
+		if err != nil {
+			log.Printf("wrong value configed in $BALANCE_ALERT")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		balanceAlert = b
+	}
+
+	if runway, presented := os.LookupEnv(types.EnvBalanceRunway); presented && balanceRunway == DefaultBalanceRunway {
+		r, err := strconv.ParseUint(runway, 0, 64)
+		if err != nil {
+			log.Printf("wrong value configed in $BALANCE_RUNWAY")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		balanceRunway = r
+	}
+
+	if webhook, presented := os.LookupEnv(types.EnvAlertWebhook); presented && alertWebhook == DefaultAlertWebhook {
+		alertWebhook = webhook
+	}
+
+	if hook, presented := os.LookupEnv(types.EnvAlertExec); presented && alertExec == DefaultAlertExec {
+		alertExec = hook
+	}
+
 	if capGasTip, presented := os.LookupEnv(types.EnvGasTipCap); presented && gasTipCap == DefaultGasTipCap {
 		gasTip, err := strconv.ParseUint(capGasTip, 0, 64)
 		if err != nil {
@@ -107,22 +287,124 @@ func MakeConfig() *types.OracleServiceConfig {
 		gasTipCap = gasTip
 	}
 
-	key, err := loadKey(keyFile, keyPassword)
-	if err != nil {
+	if strategy, presented := os.LookupEnv(types.EnvGasStrategy); presented && gasStrategy == DefaultGasStrategy {
+		gasStrategy = strategy
+	}
+
+	if tipMax, presented := os.LookupEnv(types.EnvGasTipMax); presented && gasTipMax == DefaultGasTipMax {
+		t, err := strconv.ParseUint(tipMax, 0, 64)
+		if err != nil {
+			log.Printf("wrong value configed in $GAS_TIP_MAX")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		gasTipMax = t
+	}
+
+	if feeCapMax, presented := os.LookupEnv(types.EnvGasFeeCapMax); presented && gasFeeCapMax == DefaultGasFeeCapMax {
+		f, err := strconv.ParseUint(feeCapMax, 0, 64)
+		if err != nil {
+			log.Printf("wrong value configed in $GAS_FEE_CAP_MAX")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		gasFeeCapMax = f
+	}
+
+	if limitMax, presented := os.LookupEnv(types.EnvGasLimitMax); presented && gasLimitMax == DefaultGasLimitMax {
+		l, err := strconv.ParseUint(limitMax, 0, 64)
+		if err != nil {
+			log.Printf("wrong value configed in $GAS_LIMIT_MAX")
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		gasLimitMax = l
+	}
+
+	if gasStrategy != feestrategy.Static && gasStrategy != feestrategy.Dynamic {
+		log.Printf("wrong gas strategy configed: %s, %s", gasStrategy, UsageGasStrategy)
+		helpers.PrintUsage()
+		os.Exit(1)
+	}
+
+	if gasLimitMax == 0 || (gasTipMax != 0 && gasTipMax < gasTipCap) || (gasFeeCapMax != 0 && gasFeeCapMax < gasTipCap) {
+		log.Printf("wrong gas settings configed, the ceilings of the gas settings cannot be lower than the floor of the tip")
+		helpers.PrintUsage()
+		os.Exit(1)
+	}
+
+	wsUrls := parseWSUrls(autonityWSUrl)
+	if len(wsUrls) == 0 {
+		log.Printf("wrong value configed in ws: %s", autonityWSUrl)
+		helpers.PrintUsage()
+		os.Exit(1)
+	}
+
+	// the key is only decrypted into the memory for the keystore signer, the remote signers keep it out of the process.
+	var key *keystore.Key
+	var address common.Address
+	switch signerType {
+	case signer.Keystore:
+		k, err := loadKey(keyFile, keyPassword)
+		if err != nil {
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		key, address = k, k.Address
+	case signer.Clef, signer.RPC:
+		if signerURL == "" || !common.IsHexAddress(signerAddress) {
+			log.Printf("the %s signer requires the signer URL and a valid account address", signerType)
+			helpers.PrintUsage()
+			os.Exit(1)
+		}
+		address = common.HexToAddress(signerAddress)
+	default:
+		log.Printf("wrong signer configed: %s, %s", signerType, UsageSigner)
 		helpers.PrintUsage()
 		os.Exit(1)
 	}
 
 	return &types.OracleServiceConfig{
-		GasTipCap:      gasTipCap,
-		Key:            key,
-		AutonityWSUrl:  autonityWSUrl,
-		PluginDIR:      pluginDir,
-		PluginConfFile: pluginConfFile,
-		LoggingLevel:   hclog.Level(logLevel),
+		GasStrategy:     gasStrategy,
+		GasTipCap:       gasTipCap,
+		GasTipMax:       gasTipMax,
+		GasFeeCapMax:    gasFeeCapMax,
+		GasLimitMax:     gasLimitMax,
+		Key:             key,
+		SignerType:      signerType,
+		SignerURL:       signerURL,
+		SignerAddress:   address,
+		AutonityWSUrl:   wsUrls[0],
+		AutonityWSUrls:  wsUrls,
+		PluginDIR:       pluginDir,
+		PluginConfFile:  pluginConfFile,
+		PluginVerify:    pluginVerify,
+		AggregationConf: aggregationConfFile,
+		DataDIR:         dataDir,
+		MetricsEnabled:  metricsEnabled,
+		MetricsAddr:     metricsAddr,
+		AdminEnabled:    adminEnabled,
+		AdminAddr:       adminAddr,
+		DryRun:          dryRun,
+		BalanceAlert:    balanceAlert,
+		BalanceRunway:   balanceRunway,
+		AlertWebhook:    alertWebhook,
+		AlertExec:       alertExec,
+		LoggingLevel:    hclog.Level(logLevel),
 	}
 }
 
+// parseWSUrls splits the comma separated WS endpoints of the L1 network, the first one is the primary endpoint.
+func parseWSUrls(urls string) []string {
+	var result []string
+	for _, u := range strings.Split(urls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
 func loadKey(keyFile, password string) (*keystore.Key, error) {
 	keyJson, err := os.ReadFile(keyFile)
 	if err != nil {
@@ -153,8 +435,74 @@ func LoadPluginsConfig(file string) (map[string]types.PluginConfig, error) {
 	confs := make(map[string]types.PluginConfig)
 	for _, conf := range configs {
 		c := conf
+		if c.Checksum != "" {
+			if sum, err := hex.DecodeString(c.Checksum); err != nil || len(sum) != sha256.Size {
+				return nil, fmt.Errorf("invalid SHA-256 checksum of plugin %s: %s", c.Name, c.Checksum)
+			}
+		}
+		if c.KeyFile != "" {
+			key, err := readPluginKey(&c, filepath.Dir(file))
+			if err != nil {
+				return nil, err
+			}
+			c.Key = key
+		}
 		confs[c.Name] = c
 	}
 
 	return confs, nil
 }
+
+// readPluginKey reads the API key of a plugin from its key file, a relative path is resolved against the directory of
+// the plugins' configuration file, and the key of a secrets directory is read from the file named after the plugin.
+func readPluginKey(conf *types.PluginConfig, confDir string) (string, error) {
+	if conf.Key != "" {
+		return "", fmt.Errorf("both key and key_file are set for plugin %s", conf.Name)
+	}
+
+	path := conf.KeyFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(confDir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read the key of plugin %s: %w", conf.Name, err)
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, conf.Name)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read the key of plugin %s: %w", conf.Name, err)
+	}
+	key := strings.TrimSpace(string(content))
+	if key == "" {
+		return "", fmt.Errorf("empty key file of plugin %s: %s", conf.Name, path)
+	}
+	return key, nil
+}
+
+// LoadAggregationConfig loads the aggregation strategies from the file, an empty file path means that the median is
+// applied to all symbols.
+func LoadAggregationConfig(file string) (*types.AggregationConfig, error) {
+	conf := &types.AggregationConfig{}
+	if file == "" {
+		return conf, nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(content, conf); err != nil {
+		return nil, err
+	}
+
+	// the outlier rejection of the default strategy is set by the top level outlier section.
+	if conf.Default.Outlier != nil {
+		return nil, fmt.Errorf("outlier is not taken by the default section of %s, set it at the top level", file)
+	}
+	return conf, nil
+}
@@ -1,11 +1,15 @@
 package config
 
 import (
+	feestrategy "autonity-oracle/fee_strategy"
+	"autonity-oracle/signer"
 	"autonity-oracle/types"
+	"encoding/json"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -31,7 +35,15 @@ func TestMakeConfigWithEnvironmentVariables(t *testing.T) {
 		require.NoError(t, err)
 		defer os.Unsetenv(types.EnvGasTipCap)
 
-		err = os.Setenv(types.EnvWS, "ws://127.0.0.1:30303")
+		err = os.Setenv(types.EnvGasStrategy, "dynamic")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvGasStrategy)
+
+		err = os.Setenv(types.EnvGasTipMax, "1000")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvGasTipMax)
+
+		err = os.Setenv(types.EnvWS, "ws://127.0.0.1:30303, ws://127.0.0.2:30303")
 		require.NoError(t, err)
 		defer os.Unsetenv(types.EnvWS)
 
@@ -39,12 +51,150 @@ func TestMakeConfigWithEnvironmentVariables(t *testing.T) {
 		require.NoError(t, err)
 		defer os.Unsetenv(types.EnvPluginCof)
 
+		err = os.Setenv(types.EnvAggregationConf, "./aggregation-conf.yml")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvAggregationConf)
+
+		err = os.Setenv(types.EnvDataDIR, "./round-data")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvDataDIR)
+
+		err = os.Setenv(types.EnvMetrics, "true")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvMetrics)
+
+		err = os.Setenv(types.EnvMetricsAddr, "0.0.0.0:9102")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvMetricsAddr)
+
+		err = os.Setenv(types.EnvBalanceRunway, "50")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvBalanceRunway)
+
+		err = os.Setenv(types.EnvAlertWebhook, "http://127.0.0.1:8080/alerts")
+		require.NoError(t, err)
+		defer os.Unsetenv(types.EnvAlertWebhook)
+
 		conf := MakeConfig()
 		require.Equal(t, "./", conf.PluginDIR)
 		require.Equal(t, common.HexToAddress("0xb749d3d83376276ab4ddef2d9300fb5ce70ebafe"), conf.Key.Address)
+		require.Equal(t, signer.Keystore, conf.SignerType)
+		require.Equal(t, conf.Key.Address, conf.SignerAddress)
 		require.Equal(t, hclog.Info, conf.LoggingLevel)
 		require.Equal(t, uint64(30), conf.GasTipCap)
+		require.Equal(t, feestrategy.Dynamic, conf.GasStrategy)
+		require.Equal(t, uint64(1000), conf.GasTipMax)
+		require.Equal(t, uint64(0), conf.GasFeeCapMax)
+		require.Equal(t, DefaultGasLimitMax, conf.GasLimitMax)
 		require.Equal(t, "ws://127.0.0.1:30303", conf.AutonityWSUrl)
+		require.Equal(t, []string{"ws://127.0.0.1:30303", "ws://127.0.0.2:30303"}, conf.AutonityWSUrls)
 		require.Equal(t, "./plugin-conf.yml", conf.PluginConfFile)
+		require.Equal(t, "./aggregation-conf.yml", conf.AggregationConf)
+		require.Equal(t, "./round-data", conf.DataDIR)
+		require.Equal(t, true, conf.MetricsEnabled)
+		require.Equal(t, "0.0.0.0:9102", conf.MetricsAddr)
+		require.Equal(t, DefaultBalanceAlert, conf.BalanceAlert)
+		require.Equal(t, uint64(50), conf.BalanceRunway)
+		require.Equal(t, "http://127.0.0.1:8080/alerts", conf.AlertWebhook)
+		require.Equal(t, "", conf.AlertExec)
 	})
 }
+
+func TestLoadAggregationConfig(t *testing.T) {
+	conf, err := LoadAggregationConfig(DefaultAggregationConf)
+	require.NoError(t, err)
+	require.Equal(t, "", conf.Default.Strategy)
+	require.Equal(t, 0, len(conf.Symbols))
+
+	conf, err = LoadAggregationConfig("../test_data/aggregation-conf.yml")
+	require.NoError(t, err)
+	require.Equal(t, "median", conf.Default.Strategy)
+	require.Equal(t, "weighted", conf.Symbols["EUR-USD"].Strategy)
+	require.Equal(t, float64(3), conf.Symbols["EUR-USD"].Weights["forex_currencyfreaks"])
+	require.Equal(t, "primary", conf.Symbols["NTN-USD"].Strategy)
+	require.Equal(t, []string{"pcgc_cax"}, conf.Symbols["NTN-USD"].Primary)
+	require.Equal(t, "trimmed_mean", conf.Symbols["NTN-USD"].Fallback)
+	require.Equal(t, 0.25, *conf.Symbols["NTN-USD"].Trim)
+	require.Equal(t, 0.05, conf.Outlier.MaxDeviation)
+	require.Equal(t, float64(5), conf.Outlier.MADThreshold)
+	require.Nil(t, conf.Symbols["EUR-USD"].Outlier)
+	require.Equal(t, 0.2, conf.Symbols["NTN-USD"].Outlier.MaxJump)
+
+	_, err = LoadAggregationConfig("../test_data/not-exist.yml")
+	require.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "aggregation-conf.yml")
+	require.NoError(t, os.WriteFile(file, []byte("default:\n  strategy: median\n  outlier:\n    max_jump: 0.1\n"), 0600))
+	_, err = LoadAggregationConfig(file)
+	require.Error(t, err)
+}
+
+func TestLoadPluginsConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "plugins-conf.yml")
+	content := `
+- name: pcgc_cax
+  options:
+    price: bid
+    derive: false
+  symbols:
+    NTN-USD: NTN-USDC
+- name: forex_currencyfreaks
+  key: 575aab9e47e54790bf6d502c48407c10
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+	confs, err := LoadPluginsConfig(file)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(confs))
+	cax := confs["pcgc_cax"]
+	require.Equal(t, map[string]string{"price": "bid", "derive": "false"}, cax.Options)
+	require.Equal(t, map[string]string{"NTN-USD": "NTN-USDC"}, cax.Symbols)
+	derive, err := cax.BoolOption("derive", true)
+	require.NoError(t, err)
+	require.False(t, derive)
+	_, err = cax.BoolOption("price", false)
+	require.Error(t, err)
+
+	// the options round-trip through the JSON delivered to the plugin.
+	raw, err := json.Marshal(cax)
+	require.NoError(t, err)
+	var delivered types.PluginConfig
+	require.NoError(t, json.Unmarshal(raw, &delivered))
+	require.Equal(t, cax, delivered)
+	require.Nil(t, confs["forex_currencyfreaks"].Options)
+}
+
+func TestLoadPluginsConfigKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "secrets"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secrets", "forex_currencyfreaks"), []byte("575aab9e\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "openexchange.key"), []byte("0be02ca3"), 0600))
+
+	file := filepath.Join(dir, "plugins-conf.yml")
+	content := `
+- name: forex_currencyfreaks
+  key_file: secrets
+- name: forex_openexchangerate
+  key_file: ` + filepath.Join(dir, "openexchange.key") + `
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	confs, err := LoadPluginsConfig(file)
+	require.NoError(t, err)
+	require.Equal(t, "575aab9e", confs["forex_currencyfreaks"].Key)
+	require.Equal(t, "0be02ca3", confs["forex_openexchangerate"].Key)
+
+	// the key file is not delivered to the plugin.
+	raw, err := json.Marshal(confs["forex_currencyfreaks"])
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "secrets")
+
+	for _, content = range []string{
+		"- name: forex_currencyfreaks\n  key: 575aab9e\n  key_file: secrets\n",
+		"- name: forex_currencylayer\n  key_file: secrets\n",
+		"- name: forex_currencyfreaks\n  key_file: not-exist.key\n",
+	} {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+		_, err = LoadPluginsConfig(file)
+		require.Error(t, err, content)
+	}
+}
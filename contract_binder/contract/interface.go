@@ -16,6 +16,9 @@ type ContractAPI interface {
 	GetVoters(opts *bind.CallOpts) ([]common.Address, error)
 	GetRound(opts *bind.CallOpts) (*big.Int, error)
 	WatchNewRound(opts *bind.WatchOpts, sink chan<- *OracleNewRound) (event.Subscription, error)
+	FilterNewRound(opts *bind.FilterOpts) (*OracleNewRoundIterator, error)
+	WatchVoted(opts *bind.WatchOpts, sink chan<- *OracleVoted, _voter []common.Address) (event.Subscription, error)
+	FilterVoted(opts *bind.FilterOpts, _voter []common.Address) (*OracleVotedIterator, error)
 	WatchNewSymbols(opts *bind.WatchOpts, sink chan<- *OracleNewSymbols) (event.Subscription, error)
 	GetRoundData(opts *bind.CallOpts, _round *big.Int, _symbol string) (IOracleRoundData, error)
 	LatestRoundData(opts *bind.CallOpts, _symbol string) (IOracleRoundData, error)
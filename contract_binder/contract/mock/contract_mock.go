@@ -39,6 +39,36 @@ func (m *MockContractAPI) EXPECT() *MockContractAPIMockRecorder {
 	return m.recorder
 }
 
+// FilterNewRound mocks base method.
+func (m *MockContractAPI) FilterNewRound(opts *bind.FilterOpts) (*oracle.OracleNewRoundIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterNewRound", opts)
+	ret0, _ := ret[0].(*oracle.OracleNewRoundIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterNewRound indicates an expected call of FilterNewRound.
+func (mr *MockContractAPIMockRecorder) FilterNewRound(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterNewRound", reflect.TypeOf((*MockContractAPI)(nil).FilterNewRound), opts)
+}
+
+// FilterVoted mocks base method.
+func (m *MockContractAPI) FilterVoted(opts *bind.FilterOpts, _voter []common.Address) (*oracle.OracleVotedIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterVoted", opts, _voter)
+	ret0, _ := ret[0].(*oracle.OracleVotedIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterVoted indicates an expected call of FilterVoted.
+func (mr *MockContractAPIMockRecorder) FilterVoted(opts, _voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterVoted", reflect.TypeOf((*MockContractAPI)(nil).FilterVoted), opts, _voter)
+}
+
 // GetPrecision mocks base method.
 func (m *MockContractAPI) GetPrecision(opts *bind.CallOpts) (*big.Int, error) {
 	m.ctrl.T.Helper()
@@ -203,3 +233,18 @@ func (mr *MockContractAPIMockRecorder) WatchNewSymbols(opts, sink interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchNewSymbols", reflect.TypeOf((*MockContractAPI)(nil).WatchNewSymbols), opts, sink)
 }
+
+// WatchVoted mocks base method.
+func (m *MockContractAPI) WatchVoted(opts *bind.WatchOpts, sink chan<- *oracle.OracleVoted, _voter []common.Address) (event.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchVoted", opts, sink, _voter)
+	ret0, _ := ret[0].(event.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchVoted indicates an expected call of WatchVoted.
+func (mr *MockContractAPIMockRecorder) WatchVoted(opts, sink, _voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchVoted", reflect.TypeOf((*MockContractAPI)(nil).WatchVoted), opts, sink, _voter)
+}
@@ -0,0 +1,131 @@
+package feestrategy
+
+import (
+	"autonity-oracle/types"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/hashicorp/go-hclog"
+	"math/big"
+)
+
+// The gas fee strategies of the vote transactions.
+const (
+	Static  = "static"  // the configured tip is applied, the gas limit is the configured max gas limit.
+	Dynamic = "dynamic" // the gas limit, the tip and the fee cap are derived from the L1 network on each vote.
+)
+
+var (
+	GasLimitMarginPercent = uint64(20) // the margin added to the estimated gas of a vote.
+	MaxUrgencyPercent     = int64(200) // the suggested tip is multiplied up to 200% at the end of the vote period.
+	BaseFeeMultiplier     = int64(2)   // the fee cap covers the base fee doubled, thus it survives a few full blocks.
+
+	ErrUnknownStrategy = errors.New("unknown gas fee strategy")
+	ErrNoBaseFee       = errors.New("the latest header has no base fee")
+)
+
+// NewStrategy returns the gas fee strategy set in the oracle server configuration.
+func NewStrategy(conf *types.OracleServiceConfig, logger hclog.Logger) (types.FeeStrategy, error) {
+	switch conf.GasStrategy {
+	case Static, "":
+		return NewStaticStrategy(conf.GasTipCap, conf.GasLimitMax), nil
+	case Dynamic:
+		return NewDynamicStrategy(conf.GasTipCap, conf.GasTipMax, conf.GasFeeCapMax, conf.GasLimitMax, logger), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, conf.GasStrategy)
+	}
+}
+
+// StaticStrategy applies the configured tip and gas limit to all the votes, the fee cap is left to the transactor.
+type StaticStrategy struct {
+	tip      *big.Int
+	gasLimit uint64
+}
+
+func NewStaticStrategy(tip, gasLimit uint64) *StaticStrategy {
+	return &StaticStrategy{tip: new(big.Int).SetUint64(tip), gasLimit: gasLimit}
+}
+
+func (s *StaticStrategy) Fees(_ context.Context, _ types.Blockchain, _ ethereum.CallMsg, _, _ uint64) (*types.Fees, error) {
+	return &types.Fees{GasLimit: s.gasLimit, GasTipCap: new(big.Int).Set(s.tip)}, nil
+}
+
+// DynamicStrategy estimates the gas of the vote, and follows the suggested tip and the base fee of the L1 network. The
+// suggested tip is multiplied by an urgency rising as the end of the vote period approaches, thus a vote sent late in a
+// round is more likely to be mined in time. The tip is kept between the floor and the ceiling, a zero ceiling is no
+// ceiling.
+type DynamicStrategy struct {
+	minTip      *big.Int
+	maxTip      *big.Int
+	maxFeeCap   *big.Int
+	maxGasLimit uint64
+	logger      hclog.Logger
+}
+
+func NewDynamicStrategy(minTip, maxTip, maxFeeCap, maxGasLimit uint64, logger hclog.Logger) *DynamicStrategy {
+	return &DynamicStrategy{
+		minTip:      new(big.Int).SetUint64(minTip),
+		maxTip:      new(big.Int).SetUint64(maxTip),
+		maxFeeCap:   new(big.Int).SetUint64(maxFeeCap),
+		maxGasLimit: maxGasLimit,
+		logger:      logger,
+	}
+}
+
+func (s *DynamicStrategy) Fees(ctx context.Context, client types.Blockchain, msg ethereum.CallMsg, start,
+	end uint64) (*types.Fees, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, ErrNoBaseFee
+	}
+
+	// a failed suggestion or estimation should not stop the vote, the floor and the ceiling are applied instead.
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		s.logger.Warn("suggest gas tip cap, the tip floor is applied", "error", err.Error())
+		tip = new(big.Int).Set(s.minTip)
+	}
+	tip = new(big.Int).Mul(tip, big.NewInt(urgencyPercent(header.Number.Uint64(), start, end)))
+	tip.Div(tip, big.NewInt(100))
+	if tip.Cmp(s.minTip) < 0 {
+		tip.Set(s.minTip)
+	}
+	if s.maxTip.Sign() > 0 && tip.Cmp(s.maxTip) > 0 {
+		tip.Set(s.maxTip)
+	}
+
+	feeCap := new(big.Int).Mul(header.BaseFee, big.NewInt(BaseFeeMultiplier))
+	feeCap.Add(feeCap, tip)
+	if s.maxFeeCap.Sign() > 0 && feeCap.Cmp(s.maxFeeCap) > 0 {
+		feeCap.Set(s.maxFeeCap)
+		if tip.Cmp(feeCap) > 0 {
+			tip.Set(feeCap)
+		}
+	}
+
+	gasLimit := s.maxGasLimit
+	gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		s.logger.Warn("estimate vote gas, the max gas limit is applied", "error", err.Error())
+	} else if gas = gas * (100 + GasLimitMarginPercent) / 100; gas < gasLimit {
+		gasLimit = gas
+	}
+
+	return &types.Fees{GasLimit: gasLimit, GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
+
+// urgencyPercent rises linearly from 100% at the start of the vote period to MaxUrgencyPercent at the end of it.
+func urgencyPercent(height, start, end uint64) int64 {
+	if end <= start || height <= start {
+		return 100
+	}
+	elapsed := height - start
+	if elapsed > end-start {
+		elapsed = end - start
+	}
+	return 100 + (MaxUrgencyPercent-100)*int64(elapsed)/int64(end-start)
+}
@@ -0,0 +1,110 @@
+package feestrategy
+
+import (
+	"autonity-oracle/types"
+	"autonity-oracle/types/mock"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestFeeStrategy(t *testing.T) {
+	t.Run("new strategy by configuration", func(t *testing.T) {
+		s, err := NewStrategy(&types.OracleServiceConfig{GasStrategy: Static}, hclog.NewNullLogger())
+		require.NoError(t, err)
+		require.IsType(t, &StaticStrategy{}, s)
+
+		s, err = NewStrategy(&types.OracleServiceConfig{GasStrategy: Dynamic}, hclog.NewNullLogger())
+		require.NoError(t, err)
+		require.IsType(t, &DynamicStrategy{}, s)
+
+		_, err = NewStrategy(&types.OracleServiceConfig{GasStrategy: "legacy"}, hclog.NewNullLogger())
+		require.True(t, errors.Is(err, ErrUnknownStrategy))
+	})
+
+	t.Run("static strategy applies the configured tip and gas limit", func(t *testing.T) {
+		fees, err := NewStaticStrategy(10, 3000000).Fees(context.Background(), nil, ethereum.CallMsg{}, 100, 130)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3000000), fees.GasLimit)
+		require.Equal(t, int64(10), fees.GasTipCap.Int64())
+		require.Nil(t, fees.GasFeeCap)
+	})
+
+	t.Run("dynamic strategy raises the tip as the end of the vote period approaches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		s := NewDynamicStrategy(1, 0, 0, 3000000, hclog.NewNullLogger())
+		l1Mock.EXPECT().SuggestGasTipCap(gomock.Any()).AnyTimes().Return(big.NewInt(100), nil)
+		l1Mock.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).AnyTimes().Return(uint64(100000), nil)
+
+		// at the start of the vote period, the suggested tip is applied.
+		l1Mock.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&tp.Header{Number: big.NewInt(100),
+			BaseFee: big.NewInt(1000)}, nil)
+		fees, err := s.Fees(context.Background(), l1Mock, ethereum.CallMsg{}, 100, 130)
+		require.NoError(t, err)
+		require.Equal(t, uint64(120000), fees.GasLimit)
+		require.Equal(t, int64(100), fees.GasTipCap.Int64())
+		require.Equal(t, int64(2100), fees.GasFeeCap.Int64())
+
+		// in the middle of the vote period, the tip is raised by half of the max urgency.
+		l1Mock.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&tp.Header{Number: big.NewInt(115),
+			BaseFee: big.NewInt(1000)}, nil)
+		fees, err = s.Fees(context.Background(), l1Mock, ethereum.CallMsg{}, 100, 130)
+		require.NoError(t, err)
+		require.Equal(t, int64(150), fees.GasTipCap.Int64())
+
+		// beyond the vote period, the max urgency is applied.
+		l1Mock.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&tp.Header{Number: big.NewInt(140),
+			BaseFee: big.NewInt(1000)}, nil)
+		fees, err = s.Fees(context.Background(), l1Mock, ethereum.CallMsg{}, 100, 130)
+		require.NoError(t, err)
+		require.Equal(t, int64(200), fees.GasTipCap.Int64())
+	})
+
+	t.Run("dynamic strategy keeps the fees between the floors and the ceilings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		l1Mock.EXPECT().HeaderByNumber(gomock.Any(), nil).AnyTimes().Return(&tp.Header{Number: big.NewInt(100),
+			BaseFee: big.NewInt(1000)}, nil)
+
+		// the suggested tip is below the floor, and the estimated gas is above the ceiling.
+		l1Mock.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(5), nil)
+		l1Mock.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(3000000), nil)
+		fees, err := NewDynamicStrategy(50, 500, 0, 3000000, hclog.NewNullLogger()).Fees(context.Background(),
+			l1Mock, ethereum.CallMsg{}, 100, 130)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3000000), fees.GasLimit)
+		require.Equal(t, int64(50), fees.GasTipCap.Int64())
+
+		// the suggested tip is above the ceiling, the fee cap is capped, and the estimation fails.
+		l1Mock.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(5000), nil)
+		l1Mock.EXPECT().EstimateGas(gomock.Any(), gomock.Any()).Return(uint64(0), errors.New("execution reverted"))
+		fees, err = NewDynamicStrategy(50, 500, 1800, 2000000, hclog.NewNullLogger()).Fees(context.Background(),
+			l1Mock, ethereum.CallMsg{}, 100, 130)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2000000), fees.GasLimit)
+		require.Equal(t, int64(500), fees.GasTipCap.Int64())
+		require.Equal(t, int64(1800), fees.GasFeeCap.Int64())
+	})
+
+	t.Run("dynamic strategy requires the base fee", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		l1Mock.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&tp.Header{Number: big.NewInt(100)}, nil)
+		_, err := NewDynamicStrategy(1, 0, 0, 3000000, hclog.NewNullLogger()).Fees(context.Background(), l1Mock,
+			ethereum.CallMsg{}, 100, 130)
+		require.True(t, errors.Is(err, ErrNoBaseFee))
+	})
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	adminserver "autonity-oracle/admin_server"
 	"autonity-oracle/config"
 	contract "autonity-oracle/contract_binder/contract"
 	"autonity-oracle/helpers"
+	metricsserver "autonity-oracle/metrics_server"
 	"autonity-oracle/oracle_server"
 	"autonity-oracle/types"
+	"github.com/ethereum/go-ethereum/metrics"
 	"log"
 	"os"
 	"os/signal"
@@ -18,10 +21,26 @@ func main() { //nolint
 		"\tby connecting to L1 node: %s\n \ton oracle contract address: %s \n\n\n",
 		config.Version, conf.PluginDIR, conf.AutonityWSUrl, types.OracleContractAddress)
 
+	// metrics are enabled before any of them is created, otherwise they are no-ops.
+	if conf.MetricsEnabled {
+		metrics.Enabled = true
+		ms := metricsserver.NewMetricsServer(conf.LoggingLevel, conf.MetricsAddr)
+		ms.Start()
+		defer ms.Stop()
+	}
+
+	// connect to the first reachable endpoint on startup, the oracle server fails over between them on runtime.
 	dialer := &types.L1Dialer{}
-	client, err := dialer.Dial(conf.AutonityWSUrl)
-	if err != nil {
-		log.Printf("cannot connect to Autonity network via web socket: %s", err.Error())
+	var client types.Blockchain
+	var err error
+	for _, url := range conf.AutonityWSUrls {
+		if client, err = dialer.Dial(url); err == nil {
+			conf.AutonityWSUrl = url
+			break
+		}
+		log.Printf("cannot connect to Autonity network via web socket: %s, %s", url, err.Error())
+	}
+	if client == nil {
 		helpers.PrintUsage()
 		os.Exit(1)
 	}
@@ -37,6 +56,12 @@ func main() { //nolint
 	go oracle.Start()
 	defer oracle.Stop()
 
+	if conf.AdminEnabled {
+		as := adminserver.NewAdminServer(conf.LoggingLevel, conf.AdminAddr, oracle)
+		as.Start()
+		defer as.Stop()
+	}
+
 	// Wait for interrupt signal to gracefully shut down the server with
 	// a timeout of 5 seconds.
 	quit := make(chan os.Signal, 1)
@@ -0,0 +1,142 @@
+package metricsserver
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+	"github.com/hashicorp/go-hclog"
+	"github.com/modern-go/reflect2"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// The metrics of the oracle server, they are collected in the default registry of go-ethereum's metrics, thus they are
+// no-ops unless metrics.Enabled is set before the first use of them.
+const (
+	RoundGauge             = "oracle/round"
+	ReportedRoundGauge     = "oracle/round/reported"
+	VoteSentCounter        = "oracle/vote/sent"
+	VoteFailedCounter      = "oracle/vote/failed"
+	VoteMinedCounter       = "oracle/vote/mined"
+	VoteRevertedCounter    = "oracle/vote/reverted"
+	VoteExpiredCounter     = "oracle/vote/expired"
+	VoteResubmittedCounter = "oracle/vote/resubmitted"
+	VoteConfirmedCounter   = "oracle/vote/confirmed"
+	VoteUnconfirmedCounter = "oracle/vote/unconfirmed"
+	VoteMismatchedCounter  = "oracle/vote/mismatched"
+	VoteCancelledCounter   = "oracle/vote/cancelled"
+	NonceGapCounter        = "oracle/nonce/gaps"
+	BalanceGauge           = "oracle/account/balance"
+	RunwayGauge            = "oracle/account/runway"
+	ReconnectionCounter    = "oracle/l1/reconnections"
+	PreSamplingCounter     = "oracle/presampling/samples"
+	PreSamplingHeightGauge = "oracle/presampling/height"
+
+	pricePrefix     = "oracle/price/"
+	deviationPrefix = "oracle/shadow/deviation/"
+	accuracyPrefix  = "oracle/accuracy/"
+	pluginPrefix    = "plugin/"
+)
+
+var (
+	MetricsPath      = "/debug/metrics/prometheus"
+	ShutdownDuration = 5 * time.Second
+)
+
+// MetricsServer exposes the metrics of the oracle server in the Prometheus format via an HTTP listener.
+type MetricsServer struct {
+	logger hclog.Logger
+	srv    *http.Server
+}
+
+func NewMetricsServer(logLevel hclog.Level, addr string) *MetricsServer {
+	ms := &MetricsServer{}
+	ms.logger = hclog.New(&hclog.LoggerOptions{
+		Name:   reflect2.TypeOfPtr(ms).String(),
+		Output: os.Stdout,
+		Level:  logLevel,
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, prometheus.Handler(metrics.DefaultRegistry))
+	ms.srv = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: ShutdownDuration,
+	}
+	return ms
+}
+
+// Start runs the HTTP listener in a routine, an error of the listener is logged without stopping the oracle server.
+func (ms *MetricsServer) Start() {
+	go func() {
+		ms.logger.Info("metrics server is listening", "addr", ms.srv.Addr, "path", MetricsPath)
+		if err := ms.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ms.logger.Error("metrics server", "error", err.Error())
+		}
+	}()
+}
+
+func (ms *MetricsServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownDuration)
+	defer cancel()
+	if err := ms.srv.Shutdown(ctx); err != nil {
+		ms.logger.Error("stop metrics server", "error", err.Error())
+	}
+}
+
+// PriceGauge returns the gauge of the aggregated price of a symbol.
+func PriceGauge(symbol string) metrics.GaugeFloat64 {
+	return metrics.GetOrRegisterGaugeFloat64(pricePrefix+normalise(symbol), nil)
+}
+
+// ShadowDeviationGauge returns the gauge of the relative deviation of the dry-run price of a symbol from the on-chain
+// price.
+func ShadowDeviationGauge(symbol string) metrics.GaugeFloat64 {
+	return metrics.GetOrRegisterGaugeFloat64(deviationPrefix+normalise(symbol), nil)
+}
+
+// VoteDeviationGauge returns the gauge of the relative deviation of the last revealed price of a symbol from the
+// on-chain price.
+func VoteDeviationGauge(symbol string) metrics.GaugeFloat64 {
+	return metrics.GetOrRegisterGaugeFloat64(accuracyPrefix+"symbol/"+normalise(symbol), nil)
+}
+
+// PluginDeviationGauge returns the gauge of the rolling mean deviation of the samples of a plugin from the on-chain
+// prices.
+func PluginDeviationGauge(plugin string) metrics.GaugeFloat64 {
+	return metrics.GetOrRegisterGaugeFloat64(accuracyPrefix+"plugin/"+normalise(plugin), nil)
+}
+
+// PluginSampleCounter returns the counter of the data samples collected from a plugin.
+func PluginSampleCounter(plugin string) metrics.Counter {
+	return metrics.GetOrRegisterCounter(pluginPrefix+normalise(plugin)+"/samples", nil)
+}
+
+// PluginErrorCounter returns the counter of the failed data samplings of a plugin.
+func PluginErrorCounter(plugin string) metrics.Counter {
+	return metrics.GetOrRegisterCounter(pluginPrefix+normalise(plugin)+"/errors", nil)
+}
+
+// PluginOutlierCounter returns the counter of the samples of a plugin which are excluded as outliers.
+func PluginOutlierCounter(plugin string) metrics.Counter {
+	return metrics.GetOrRegisterCounter(pluginPrefix+normalise(plugin)+"/outliers", nil)
+}
+
+// PluginLatencyTimer returns the timer of the data sampling latency of a plugin.
+func PluginLatencyTimer(plugin string) metrics.Timer {
+	return metrics.GetOrRegisterTimer(pluginPrefix+normalise(plugin)+"/latency", nil)
+}
+
+// normalise replaces those characters which are not allowed in a Prometheus metric name, for example the "-" in symbols.
+func normalise(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '/' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,47 @@
+package metricsserver
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/hashicorp/go-hclog"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMetricsServer(t *testing.T) {
+	metrics.Enabled = true
+	defer func() {
+		metrics.Enabled = false
+	}()
+
+	port, err := freeport.GetFreePort()
+	require.NoError(t, err)
+	ms := NewMetricsServer(hclog.Info, fmt.Sprintf("127.0.0.1:%d", port))
+	ms.Start()
+	defer ms.Stop()
+
+	metrics.GetOrRegisterGauge(RoundGauge, nil).Update(100)
+	PriceGauge("NTN-USD").Update(10.5)
+	PluginSampleCounter("forex_currencyfreaks").Inc(6)
+	PluginErrorCounter("forex_currencyfreaks").Inc(1)
+
+	var body []byte
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, MetricsPath)) //nolint
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		body, err = io.ReadAll(resp.Body)
+		return err == nil && resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.Contains(t, string(body), "oracle_round 100")
+	require.Contains(t, string(body), "oracle_price_NTN_USD 10.5")
+	require.Contains(t, string(body), "plugin_forex_currencyfreaks_samples 6")
+	require.Contains(t, string(body), "plugin_forex_currencyfreaks_errors 1")
+}
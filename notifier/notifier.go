@@ -0,0 +1,263 @@
+package notifier
+
+import (
+	"autonity-oracle/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/modern-go/reflect2"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	SinkTimeout    = 30 * time.Second // the max time to deliver an event to a sink, the retries included.
+	WebhookTimeout = 5 * time.Second  // the max time of a single post of an event.
+	QueueSize      = 64               // the events exceeding the queue are dropped, thus a slow sink cannot block the oracle server.
+	WebhookRetries = 3                // the max retries to post an event, the backoff is doubled on each retry.
+	RetryBackoff   = time.Second
+)
+
+// The types of the operational events of the oracle server.
+const (
+	EventBalanceLow       = "balance.low"
+	EventBalanceRecovered = "balance.recovered"
+	EventPluginCrashed    = "plugin.crashed"
+	EventPluginReplaced   = "plugin.replaced"
+	EventL1Lost           = "l1.lost"
+	EventL1Restored       = "l1.restored"
+	EventVoteSubmitted    = "vote.submitted"
+	EventVoteFailed       = "vote.failed"
+	EventSymbolsChanged   = "symbols.changed"
+	EventPriceUnavailable = "price.unavailable"
+)
+
+// The severities of the events.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Event is an operational event of the oracle server delivered to the sinks.
+type Event struct {
+	Type     string            `json:"type"`
+	Severity string            `json:"severity"`
+	Time     time.Time         `json:"time"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+func NewEvent(eventType, severity, message string, fields map[string]string) *Event {
+	return &Event{Type: eventType, Severity: severity, Time: time.Now().UTC(), Message: message, Fields: fields}
+}
+
+// Sink delivers the events to the operators.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *Event) error
+}
+
+// NewSinks returns the sinks set in the oracle server configuration, the events are always logged. Multiple webhooks and
+// executables are separated by commas.
+func NewSinks(conf *types.OracleServiceConfig, logger hclog.Logger) []Sink {
+	sinks := []Sink{NewLogSink(logger)}
+	for _, url := range splitList(conf.AlertWebhook) {
+		sinks = append(sinks, NewWebhookSink(url))
+	}
+	for _, path := range splitList(conf.AlertExec) {
+		sinks = append(sinks, NewExecSink(path))
+	}
+	return sinks
+}
+
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// LogSink logs the events with the levels of their severities.
+type LogSink struct {
+	logger hclog.Logger
+}
+
+func NewLogSink(logger hclog.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Send(_ context.Context, event *Event) error {
+	level := hclog.Info
+	switch event.Severity {
+	case SeverityWarning:
+		level = hclog.Warn
+	case SeverityCritical:
+		level = hclog.Error
+	}
+	s.logger.Log(level, event.Message, "event", event.Type, "fields", event.Fields)
+	return nil
+}
+
+// WebhookSink posts the events in JSON to an HTTP endpoint, the post is retried on a connection error or on a server side
+// error status.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: WebhookTimeout}}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := RetryBackoff
+	for retry := 0; ; retry++ {
+		var retryable bool
+		retryable, err = s.post(ctx, body)
+		if err == nil || !retryable || retry >= WebhookRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the event once, it returns if a failed post is worth a retry.
+func (s *WebhookSink) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retryable, fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return false, nil
+}
+
+// ExecSink runs a local executable for each event, the event is passed in JSON on the stdin, and its type, severity and
+// message are set in the environment variables ORACLE_EVENT_TYPE, ORACLE_EVENT_SEVERITY and ORACLE_EVENT_MESSAGE.
+type ExecSink struct {
+	path string
+}
+
+func NewExecSink(path string) *ExecSink {
+	return &ExecSink{path: path}
+}
+
+func (s *ExecSink) Name() string {
+	return "exec"
+}
+
+func (s *ExecSink) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, s.path) //nolint
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "ORACLE_EVENT_TYPE="+event.Type, "ORACLE_EVENT_SEVERITY="+event.Severity,
+		"ORACLE_EVENT_MESSAGE="+event.Message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Notifier delivers the events to the sinks in a routine, thus the event loop of the oracle server is not blocked by
+// the sinks.
+type Notifier struct {
+	logger hclog.Logger
+	sinks  []Sink
+	queue  chan *Event
+	doneCh chan struct{}
+}
+
+func NewNotifier(logLevel hclog.Level, sinks ...Sink) *Notifier {
+	n := &Notifier{
+		sinks:  sinks,
+		queue:  make(chan *Event, QueueSize),
+		doneCh: make(chan struct{}),
+	}
+	n.logger = hclog.New(&hclog.LoggerOptions{
+		Name:   reflect2.TypeOfPtr(n).String(),
+		Output: os.Stdout,
+		Level:  logLevel,
+	})
+	return n
+}
+
+// Notify queues an event to be delivered, the event is dropped if the queue is full. A nil notifier discards the
+// events.
+func (n *Notifier) Notify(event *Event) {
+	if n == nil {
+		return
+	}
+	select {
+	case n.queue <- event:
+	default:
+		n.logger.Warn("notification queue is full, event dropped", "event", event.Type)
+	}
+}
+
+// Start delivers the queued events in a routine until the notifier is stopped.
+func (n *Notifier) Start() {
+	go func() {
+		for {
+			select {
+			case event := <-n.queue:
+				n.deliver(event)
+			case <-n.doneCh:
+				return
+			}
+		}
+	}()
+}
+
+func (n *Notifier) Stop() {
+	close(n.doneCh)
+}
+
+func (n *Notifier) deliver(event *Event) {
+	for _, s := range n.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), SinkTimeout)
+		if err := s.Send(ctx, event); err != nil {
+			n.logger.Error("deliver event", "sink", s.Name(), "event", event.Type, "error", err.Error())
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"autonity-oracle/types"
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotifier(t *testing.T) {
+	t.Run("new sinks by configuration", func(t *testing.T) {
+		sinks := NewSinks(&types.OracleServiceConfig{}, hclog.NewNullLogger())
+		require.Equal(t, 1, len(sinks))
+		require.Equal(t, "log", sinks[0].Name())
+
+		sinks = NewSinks(&types.OracleServiceConfig{AlertWebhook: "http://127.0.0.1:8080, http://127.0.0.2:8080",
+			AlertExec: "./hook.sh"}, hclog.NewNullLogger())
+		require.Equal(t, 4, len(sinks))
+		require.Equal(t, "webhook", sinks[1].Name())
+		require.Equal(t, "webhook", sinks[2].Name())
+		require.Equal(t, "exec", sinks[3].Name())
+	})
+
+	t.Run("webhook sink posts the event in JSON", func(t *testing.T) {
+		received := make(chan *Event, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			var event Event
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+			received <- &event
+		}))
+		defer srv.Close()
+
+		n := NewNotifier(hclog.NoLevel, NewWebhookSink(srv.URL))
+		n.Start()
+		defer n.Stop()
+		n.Notify(NewEvent(EventBalanceLow, SeverityCritical, "low balance", map[string]string{"balance": "100"}))
+
+		event := <-received
+		require.Equal(t, "balance.low", event.Type)
+		require.Equal(t, "low balance", event.Message)
+		require.Equal(t, "100", event.Fields["balance"])
+	})
+
+	t.Run("webhook sink retries on a server side error", func(t *testing.T) {
+		backoff := RetryBackoff
+		RetryBackoff = time.Millisecond
+		defer func() { RetryBackoff = backoff }()
+
+		var posts int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			posts++
+			if posts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer srv.Close()
+
+		event := NewEvent(EventVoteFailed, SeverityWarning, "vote failed", nil)
+		require.NoError(t, NewWebhookSink(srv.URL).Send(context.Background(), event))
+		require.Equal(t, 3, posts)
+	})
+
+	t.Run("webhook sink fails on a non-2xx status", func(t *testing.T) {
+		var posts int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			posts++
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		event := NewEvent(EventBalanceLow, SeverityCritical, "low balance", nil)
+		require.Error(t, NewWebhookSink(srv.URL).Send(context.Background(), event))
+		// a client side error is not retried.
+		require.Equal(t, 1, posts)
+	})
+
+	t.Run("exec sink runs the hook with the event", func(t *testing.T) {
+		dir := t.TempDir()
+		out := filepath.Join(dir, "event.json")
+		hook := filepath.Join(dir, "hook.sh")
+		script := "#!/bin/sh\necho \"$ORACLE_EVENT_TYPE\" > " + out + ".type\ncat > " + out + "\n"
+		require.NoError(t, os.WriteFile(hook, []byte(script), 0700)) //nolint
+
+		event := NewEvent(EventBalanceLow, SeverityCritical, "low balance", nil)
+		require.NoError(t, NewExecSink(hook).Send(context.Background(), event))
+
+		content, err := os.ReadFile(out)
+		require.NoError(t, err)
+		var delivered Event
+		require.NoError(t, json.Unmarshal(content, &delivered))
+		require.Equal(t, "low balance", delivered.Message)
+		require.Equal(t, SeverityCritical, delivered.Severity)
+		eventType, err := os.ReadFile(out + ".type")
+		require.NoError(t, err)
+		require.Equal(t, "balance.low\n", string(eventType))
+
+		require.Error(t, NewExecSink(filepath.Join(dir, "missing.sh")).Send(context.Background(), event))
+	})
+}
@@ -0,0 +1,195 @@
+package oracleserver
+
+import (
+	metricsserver "autonity-oracle/metrics_server"
+	"autonity-oracle/types"
+	"errors"
+	"github.com/shopspring/decimal"
+	"math/big"
+	"sort"
+)
+
+var (
+	AccuracyWindow        = 100 // the number of the latest rounds kept in the rolling accuracy statistics.
+	AccuracySummaryRounds = 10  // the accuracy summary is logged once every 10 scored rounds.
+
+	// OnChainRoundSuccess is the status of a round data aggregated successfully by the oracle contract.
+	OnChainRoundSuccess = big.NewInt(0)
+
+	errNotAggregated     = errors.New("the round is not aggregated yet")
+	errAggregationFailed = errors.New("the on-chain aggregation of the round failed")
+)
+
+// aggregatedRound returns the round in which the prices of a round data are aggregated by the oracle contract, the
+// prices committed in a round are revealed and aggregated in the next round.
+func aggregatedRound(round uint64) uint64 {
+	return round + 1
+}
+
+// onChainPrice returns the price of a symbol aggregated by the oracle contract in a round.
+func (os *OracleServer) onChainPrice(round uint64, symbol string) (decimal.Decimal, error) {
+	roundID := new(big.Int).SetUint64(round)
+	rd, err := os.oracleContract.GetRoundData(nil, roundID, symbol)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if rd.Round == nil || rd.Round.Cmp(roundID) != 0 {
+		return decimal.Decimal{}, errNotAggregated
+	}
+	if rd.Status == nil || rd.Status.Cmp(OnChainRoundSuccess) != 0 || rd.Price == nil || rd.Price.Sign() <= 0 {
+		return decimal.Decimal{}, errAggregationFailed
+	}
+	return decimal.NewFromBigInt(rd.Price, 0).Div(os.pricePrecision), nil
+}
+
+// deviation returns the relative deviation of a price from the on-chain price.
+func deviation(price, onChain decimal.Decimal) float64 {
+	d, _ := price.Sub(onChain).Div(onChain).Abs().Float64()
+	return d
+}
+
+// accuracyRecord is the score of a symbol or a plugin in a round.
+type accuracyRecord struct {
+	deviation float64
+	failed    bool
+}
+
+// accuracyStats keep the scores of a symbol or a plugin in the latest AccuracyWindow rounds.
+type accuracyStats struct {
+	records []accuracyRecord
+}
+
+func (s *accuracyStats) add(r accuracyRecord) {
+	s.records = append(s.records, r)
+	if len(s.records) > AccuracyWindow {
+		s.records = s.records[len(s.records)-AccuracyWindow:]
+	}
+}
+
+func (s *accuracyStats) summary(name string) types.AccuracyStats {
+	stats := types.AccuracyStats{Name: name, Rounds: len(s.records)}
+	var sum float64
+	for _, r := range s.records {
+		if r.failed {
+			stats.Failed++
+			continue
+		}
+		sum += r.deviation
+		if r.deviation > stats.MaxDeviation {
+			stats.MaxDeviation = r.deviation
+		}
+	}
+	if scored := stats.Rounds - stats.Failed; scored > 0 {
+		stats.MeanDeviation = sum / float64(scored)
+	}
+	return stats
+}
+
+// accuracyScorer scores the revealed prices against the on-chain aggregated prices by symbols, and by the plugins
+// contributed to them, thus the data providers dragging the votes away from the consensus can be found. The zero value
+// is ready to use.
+type accuracyScorer struct {
+	symbols    map[string]*accuracyStats
+	plugins    map[string]*accuracyStats
+	lastScored uint64 // the last on-chain round scored.
+	scored     int    // the number of the rounds scored.
+}
+
+func (a *accuracyScorer) add(stats map[string]*accuracyStats, name string, r accuracyRecord) map[string]*accuracyStats {
+	if stats == nil {
+		stats = make(map[string]*accuracyStats)
+	}
+	if _, ok := stats[name]; !ok {
+		stats[name] = &accuracyStats{}
+	}
+	stats[name].add(r)
+	return stats
+}
+
+func (a *accuracyScorer) addSymbol(symbol string, r accuracyRecord) {
+	a.symbols = a.add(a.symbols, symbol, r)
+}
+
+func (a *accuracyScorer) addPlugin(plugin string, r accuracyRecord) {
+	a.plugins = a.add(a.plugins, plugin, r)
+}
+
+// report returns the accuracy statistics, the least accurate symbols and plugins come first.
+func (a *accuracyScorer) report() types.AccuracyReport {
+	summaries := func(stats map[string]*accuracyStats) []types.AccuracyStats {
+		result := []types.AccuracyStats{}
+		for name, s := range stats {
+			result = append(result, s.summary(name))
+		}
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].MeanDeviation != result[j].MeanDeviation {
+				return result[i].MeanDeviation > result[j].MeanDeviation
+			}
+			return result[i].Name < result[j].Name
+		})
+		return result
+	}
+	return types.AccuracyReport{Symbols: summaries(a.symbols), Plugins: summaries(a.plugins)}
+}
+
+// scoreRound scores the prices revealed in the last round with the on-chain prices aggregated in it. It is called on
+// the rotation to the new round, when the aggregation of the last round is done.
+func (os *OracleServer) scoreRound(newRound uint64) {
+	if newRound < 2 {
+		return
+	}
+	aggregated := newRound - 1
+	if aggregated <= os.accuracy.lastScored {
+		return
+	}
+
+	revealed, ok := os.roundData[aggregated-1]
+	if !ok {
+		os.logger.Debug("no revealed round data to score", "round", aggregated)
+		return
+	}
+
+	for _, s := range revealed.Symbols {
+		onChain, err := os.onChainPrice(aggregated, s)
+		if errors.Is(err, errAggregationFailed) {
+			os.logger.Warn("on-chain aggregation failed", "round", aggregated, "symbol", s)
+			os.accuracy.addSymbol(s, accuracyRecord{failed: true})
+			continue
+		}
+		if err != nil {
+			os.logger.Error("get on-chain round price", "round", aggregated, "symbol", s, "error", err.Error())
+			return
+		}
+
+		if p, ok := revealed.Prices[s]; ok {
+			d := deviation(p.Price, onChain)
+			os.accuracy.addSymbol(s, accuracyRecord{deviation: d})
+			metricsserver.VoteDeviationGauge(s).Update(d)
+			os.logger.Debug("vote accuracy", "round", aggregated, "symbol", s, "voted", p.Price.String(),
+				"on-chain", onChain.String(), "deviation", d)
+		}
+
+		for plugin, price := range revealed.Sources[s] {
+			os.accuracy.addPlugin(plugin, accuracyRecord{deviation: deviation(price, onChain)})
+		}
+	}
+	os.accuracy.lastScored = aggregated
+	os.accuracy.scored++
+
+	if os.accuracy.scored%AccuracySummaryRounds == 0 {
+		os.logAccuracySummary()
+	}
+}
+
+func (os *OracleServer) logAccuracySummary() {
+	report := os.accuracy.report()
+	for _, s := range report.Symbols {
+		os.logger.Info("vote accuracy summary by symbol", "symbol", s.Name, "rounds", s.Rounds, "failed", s.Failed,
+			"mean deviation", s.MeanDeviation, "max deviation", s.MaxDeviation)
+	}
+	for _, p := range report.Plugins {
+		metricsserver.PluginDeviationGauge(p.Name).Update(p.MeanDeviation)
+		os.logger.Info("vote accuracy summary by plugin", "plugin", p.Name, "rounds", p.Rounds,
+			"mean deviation", p.MeanDeviation, "max deviation", p.MaxDeviation)
+	}
+}
@@ -0,0 +1,185 @@
+package oracleserver
+
+import (
+	"autonity-oracle/config"
+	"autonity-oracle/helpers"
+	"autonity-oracle/types"
+	"sort"
+	"time"
+)
+
+// AdminCallTimeout is the max time of an admin call to be picked up by the event loop of the oracle server.
+var AdminCallTimeout = 10 * time.Second
+
+// execute runs the call of the admin API in the event loop of the oracle server, thus the states of the oracle server
+// are accessed without races with the round and the plugin management routines.
+func (os *OracleServer) execute(call func()) error {
+	done := make(chan struct{})
+	select {
+	case os.chAdminCall <- func() { call(); close(done) }:
+	case <-time.After(AdminCallTimeout):
+		return types.ErrServerBusy
+	}
+	<-done
+	return nil
+}
+
+// Plugins returns the runtime states of the loaded plugins ordered by their names.
+func (os *OracleServer) Plugins() ([]types.PluginInfo, error) {
+	plugins := []types.PluginInfo{}
+	err := os.execute(func() {
+		for _, p := range os.pluginSet {
+			plugins = append(plugins, types.PluginInfo{
+				Name:      p.Name(),
+				Version:   p.Version(),
+				Protocol:  p.ProtocolVersion(),
+				StartTime: p.StartTime(),
+				Exited:    p.Exited(),
+			})
+		}
+	})
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, err
+}
+
+// Samples returns the buffered samples of a plugin by symbols.
+func (os *OracleServer) Samples(name string) (map[string][]types.Price, error) {
+	var samples map[string][]types.Price
+	found := false
+	err := os.execute(func() {
+		if p, ok := os.pluginSet[name]; ok {
+			samples, found = p.Samples(), true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, types.ErrPluginNotFound
+	}
+	return samples, nil
+}
+
+// RoundState returns the state of the current round.
+func (os *OracleServer) RoundState() (types.RoundState, error) {
+	var state types.RoundState
+	err := os.execute(func() {
+		state = types.RoundState{
+			Round:        os.curRound,
+			VotePeriod:   os.votePeriod,
+			SampleTS:     os.curSampleTS,
+			SampleHeight: os.curSampleHeight,
+		}
+	})
+	return state, err
+}
+
+// RecentRoundData returns the last n round data buffered by the oracle server, the latest round comes first.
+func (os *OracleServer) RecentRoundData(n int) ([]types.RoundData, error) {
+	var rounds []types.RoundData
+	err := os.execute(func() {
+		for _, rd := range os.roundData {
+			rounds = append(rounds, *rd)
+		}
+	})
+	sort.Slice(rounds, func(i, j int) bool {
+		return rounds[i].RoundID > rounds[j].RoundID
+	})
+	if n >= 0 && len(rounds) > n {
+		rounds = rounds[:n]
+	}
+	return rounds, err
+}
+
+// ShadowReports returns the last n deviation reports of the dry-run mode, the latest round comes first.
+func (os *OracleServer) ShadowReports(n int) ([]types.DeviationReport, error) {
+	var reports []types.DeviationReport
+	err := os.execute(func() {
+		reports = append(reports, os.shadowReports...)
+	})
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Round > reports[j].Round
+	})
+	if n >= 0 && len(reports) > n {
+		reports = reports[:n]
+	}
+	return reports, err
+}
+
+// Accuracy returns the rolling accuracy statistics of the revealed prices by symbols and by plugins.
+func (os *OracleServer) Accuracy() (types.AccuracyReport, error) {
+	var report types.AccuracyReport
+	err := os.execute(func() {
+		report = os.accuracy.report()
+	})
+	return report, err
+}
+
+// ReloadPlugin restarts a plugin with its latest configuration, a plugin unloaded before is loaded again.
+func (os *OracleServer) ReloadPlugin(name string) error {
+	var err error
+	if e := os.execute(func() { err = os.reloadPlugin(name) }); e != nil {
+		return e
+	}
+	return err
+}
+
+// UnloadPlugin stops a plugin, it is not loaded by the runtime discovery until it is reloaded via the admin API.
+func (os *OracleServer) UnloadPlugin(name string) error {
+	var err error
+	if e := os.execute(func() { err = os.unloadPlugin(name) }); e != nil {
+		return e
+	}
+	return err
+}
+
+func (os *OracleServer) reloadPlugin(name string) error {
+	plugConfs, err := config.LoadPluginsConfig(os.pluginConfFile)
+	if err != nil {
+		os.logger.Error("cannot load plugin configuration", "error", err.Error())
+		return err
+	}
+
+	binaries, err := helpers.ListPlugins(os.pluginDIR)
+	if err != nil {
+		os.logger.Error("list plugin", "error", err.Error())
+		return err
+	}
+
+	for _, f := range binaries {
+		if f.Name() != name {
+			continue
+		}
+
+		delete(os.unloadedPlugins, name)
+
+		// the running plugin is stopped only once the new one is set up, thus a failed reload keeps it serving.
+		os.logger.Info("reloading plugin via admin API", "name", name)
+		pConf := plugConfs[name]
+		pluginWrapper, err := os.setupNewPlugin(name, &pConf)
+		if err != nil {
+			return err
+		}
+		if p, ok := os.pluginSet[name]; ok {
+			p.Close()
+		}
+		os.pluginSet[name] = pluginWrapper
+		return nil
+	}
+	return types.ErrPluginNotFound
+}
+
+func (os *OracleServer) unloadPlugin(name string) error {
+	p, ok := os.pluginSet[name]
+	if !ok {
+		return types.ErrPluginNotFound
+	}
+
+	os.logger.Info("unloading plugin via admin API", "name", name)
+	p.Close()
+	delete(os.pluginSet, name)
+	os.unloadedPlugins[name] = struct{}{}
+	return nil
+}
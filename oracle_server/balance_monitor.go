@@ -0,0 +1,129 @@
+package oracleserver
+
+import (
+	metricsserver "autonity-oracle/metrics_server"
+	"autonity-oracle/notifier"
+	"context"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+var (
+	BalanceTimeout      = 5 * time.Second // the max time to query the balance of the oracle server account.
+	RunwayWindow        = 20              // the runway is estimated with the average cost of the latest 20 votes.
+	BalanceAlertRounds  = 60              // the low balance alert is repeated once every 60 rounds until the account is topped up.
+	TopUpRunwayMultiple = int64(2)        // the top-up requested in an alert restores twice the runway threshold.
+)
+
+// balanceMonitor estimates the runway of the oracle server account, that is the number of the rounds it can still vote
+// in, from the balance and the costs of the recent votes.
+type balanceMonitor struct {
+	minBalance *big.Int // the balance threshold to alert.
+	minRunway  uint64   // the runway threshold to alert, in rounds.
+	costs      []*big.Int
+	alerted    bool   // the balance is low, and it is alerted.
+	alertedAt  uint64 // the round of the last low balance alert.
+}
+
+func newBalanceMonitor(minBalance, minRunway uint64) *balanceMonitor {
+	return &balanceMonitor{minBalance: new(big.Int).SetUint64(minBalance), minRunway: minRunway}
+}
+
+func (b *balanceMonitor) addCost(cost *big.Int) {
+	b.costs = append(b.costs, cost)
+	if len(b.costs) > RunwayWindow {
+		b.costs = b.costs[len(b.costs)-RunwayWindow:]
+	}
+}
+
+// avgCost returns the average cost of the recent votes, it is nil without any vote.
+func (b *balanceMonitor) avgCost() *big.Int {
+	if len(b.costs) == 0 {
+		return nil
+	}
+	sum := new(big.Int)
+	for _, c := range b.costs {
+		sum.Add(sum, c)
+	}
+	return sum.Div(sum, big.NewInt(int64(len(b.costs))))
+}
+
+// runway returns the number of the rounds the balance can still pay for, and false if there is no cost to estimate it.
+func (b *balanceMonitor) runway(balance *big.Int) (uint64, bool) {
+	cost := b.avgCost()
+	if cost == nil || cost.Sign() == 0 {
+		return 0, false
+	}
+	return new(big.Int).Div(balance, cost).Uint64(), true
+}
+
+// checkBalance records the cost of a vote, and alerts the operators via the sinks of the notifier if the balance or the
+// runway of the oracle server account drops below the thresholds.
+func (os *OracleServer) checkBalance(vote *tp.Transaction) {
+	monitor := os.balanceMonitor
+	monitor.addCost(vote.Cost())
+
+	ctx, cancel := context.WithTimeout(context.Background(), BalanceTimeout)
+	defer cancel()
+	balance, err := os.client.BalanceAt(ctx, os.signer.Address(), nil)
+	if err != nil {
+		os.logger.Error("cannot get account balance", "error", err.Error())
+		return
+	}
+
+	fBalance, _ := new(big.Float).SetInt(balance).Float64()
+	metrics.GetOrRegisterGaugeFloat64(metricsserver.BalanceGauge, nil).Update(fBalance)
+	runway, estimated := monitor.runway(balance)
+	if estimated {
+		metrics.GetOrRegisterGauge(metricsserver.RunwayGauge, nil).Update(int64(runway))
+	}
+	os.logger.Info("oracle server account", "address", os.signer.Address(), "remaining balance", balance.String(),
+		"runway rounds", runway)
+
+	low := balance.Cmp(monitor.minBalance) <= 0 || (estimated && runway < monitor.minRunway)
+	if !low {
+		if monitor.alerted {
+			monitor.alerted = false
+			os.notifier.Notify(notifier.NewEvent(notifier.EventBalanceRecovered, notifier.SeverityInfo,
+				"oracle account balance recovered",
+				os.balanceFields(balance, runway)))
+		}
+		return
+	}
+
+	if monitor.alerted && os.curRound < monitor.alertedAt+uint64(BalanceAlertRounds) {
+		return
+	}
+	monitor.alerted, monitor.alertedAt = true, os.curRound
+
+	// request a top-up which restores a multiple of the runway threshold.
+	fields := os.balanceFields(balance, runway)
+	if cost := monitor.avgCost(); cost != nil {
+		target := new(big.Int).Mul(cost, new(big.Int).SetUint64(monitor.minRunway))
+		target.Mul(target, big.NewInt(TopUpRunwayMultiple))
+		if target.Cmp(monitor.minBalance) < 0 {
+			target.Set(monitor.minBalance)
+		}
+		if topUp := target.Sub(target, balance); topUp.Sign() > 0 {
+			fields["topUp"] = topUp.String()
+		}
+	}
+	os.notifier.Notify(notifier.NewEvent(notifier.EventBalanceLow, notifier.SeverityCritical,
+		"oracle account has too less balance left for data reporting", fields))
+}
+
+func (os *OracleServer) balanceFields(balance *big.Int, runway uint64) map[string]string {
+	fields := map[string]string{
+		"address": os.signer.Address().Hex(),
+		"balance": balance.String(),
+		"round":   strconv.FormatUint(os.curRound, 10),
+	}
+	if cost := os.balanceMonitor.avgCost(); cost != nil {
+		fields["voteCost"] = cost.String()
+		fields["runway"] = strconv.FormatUint(runway, 10)
+	}
+	return fields
+}
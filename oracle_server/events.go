@@ -0,0 +1,50 @@
+package oracleserver
+
+import (
+	"autonity-oracle/notifier"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"strconv"
+	"strings"
+)
+
+func (os *OracleServer) notifyL1Lost() {
+	os.notifier.Notify(notifier.NewEvent(notifier.EventL1Lost, notifier.SeverityCritical,
+		"lost connectivity with Autonity L1 node", map[string]string{"ws": os.l1WSUrl}))
+}
+
+func (os *OracleServer) notifyL1Restored() {
+	os.notifier.Notify(notifier.NewEvent(notifier.EventL1Restored, notifier.SeverityInfo,
+		"restored connectivity with Autonity L1 node", map[string]string{"ws": os.l1WSUrl,
+			"round": strconv.FormatUint(os.curRound, 10)}))
+}
+
+// notifyVote notifies the outcome of sending the vote of a round.
+func (os *OracleServer) notifyVote(round uint64, tx *tp.Transaction, err error) {
+	fields := map[string]string{"round": strconv.FormatUint(round, 10)}
+	if err != nil {
+		fields["error"] = err.Error()
+		os.notifier.Notify(notifier.NewEvent(notifier.EventVoteFailed, notifier.SeverityWarning, "vote failed",
+			fields))
+		return
+	}
+	fields["tx"] = tx.Hash().Hex()
+	fields["nonce"] = strconv.FormatUint(tx.Nonce(), 10)
+	os.notifier.Notify(notifier.NewEvent(notifier.EventVoteSubmitted, notifier.SeverityInfo, "vote submitted",
+		fields))
+}
+
+func (os *OracleServer) notifyPlugin(eventType, severity, message, name string) {
+	os.notifier.Notify(notifier.NewEvent(eventType, severity, message, map[string]string{"plugin": name}))
+}
+
+func (os *OracleServer) notifySymbolsChanged(added []string) {
+	os.notifier.Notify(notifier.NewEvent(notifier.EventSymbolsChanged, notifier.SeverityInfo, "symbol set changed",
+		map[string]string{"added": strings.Join(added, ","), "symbols": strings.Join(os.symbols, ",")}))
+}
+
+// notifyPriceUnavailable notifies the symbols without any price to be reported in a round.
+func (os *OracleServer) notifyPriceUnavailable(round uint64, symbols []string) {
+	os.notifier.Notify(notifier.NewEvent(notifier.EventPriceUnavailable, notifier.SeverityWarning,
+		"no price available for symbols", map[string]string{"round": strconv.FormatUint(round, 10),
+			"symbols": strings.Join(symbols, ",")}))
+}
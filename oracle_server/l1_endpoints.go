@@ -0,0 +1,138 @@
+package oracleserver
+
+import (
+	contract "autonity-oracle/contract_binder/contract"
+	"autonity-oracle/types"
+	"sort"
+	"time"
+)
+
+var (
+	MaxEndpointScore    = 100              // the score of a healthy endpoint.
+	EndpointScoreReward = 10               // the score gained by an endpoint on a successful health check.
+	StallTimeout        = 60 * time.Second // the connected node is unhealthy if its height does not grow in this period.
+)
+
+// l1Endpoint is a WS endpoint of the L1 network with its health score. The score grows on the successful health checks
+// and it is halved on a failure, thus a flapping endpoint is ranked behind the stable ones.
+type l1Endpoint struct {
+	url   string
+	score int
+}
+
+func (e *l1Endpoint) succeed() {
+	e.score += EndpointScoreReward
+	if e.score > MaxEndpointScore {
+		e.score = MaxEndpointScore
+	}
+}
+
+func (e *l1Endpoint) fail() {
+	e.score /= 2
+}
+
+// l1Endpoints are the configured endpoints of the L1 network, the oracle server is connected to one of them at a time.
+type l1Endpoints struct {
+	endpoints []*l1Endpoint
+	current   *l1Endpoint
+
+	lastHeight   uint64    // the last height reported by the connected node.
+	lastHeightAt time.Time // the time when the last height was observed to grow.
+}
+
+func newL1Endpoints(urls []string, current string) *l1Endpoints {
+	e := &l1Endpoints{lastHeightAt: time.Now()}
+	for _, u := range urls {
+		ep := &l1Endpoint{url: u, score: MaxEndpointScore}
+		if u == current {
+			e.current = ep
+		}
+		e.endpoints = append(e.endpoints, ep)
+	}
+	if e.current == nil {
+		e.current = &l1Endpoint{url: current, score: MaxEndpointScore}
+		e.endpoints = append([]*l1Endpoint{e.current}, e.endpoints...)
+	}
+	return e
+}
+
+// ranked returns the endpoints by their scores in descending order, the configured order is kept on a tie.
+func (e *l1Endpoints) ranked() []*l1Endpoint {
+	ranked := make([]*l1Endpoint, len(e.endpoints))
+	copy(ranked, e.endpoints)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked
+}
+
+// observe checks the height reported by the connected node, it returns false once the height stalls for StallTimeout.
+func (e *l1Endpoints) observe(height uint64, now time.Time) bool {
+	if height > e.lastHeight {
+		e.lastHeight, e.lastHeightAt = height, now
+		return true
+	}
+	return now.Sub(e.lastHeightAt) < StallTimeout
+}
+
+// bindOracle binds the oracle contract on a connection of the L1 network.
+func bindOracle(client types.Blockchain) (contract.ContractAPI, error) {
+	return contract.NewOracle(types.OracleContractAddress, client)
+}
+
+// failover connects to the healthiest L1 endpoint, then the states are synchronized, and the events of the oracle
+// contract are re-subscribed on the new connection. The current endpoint is retried too, as it may be the only one.
+func (os *OracleServer) failover() error {
+	os.unsubscribe()
+	for _, ep := range os.l1Endpoints.ranked() {
+		client, err := os.dialer.Dial(ep.url)
+		if err != nil {
+			os.logger.Warn("cannot connect to L1 endpoint", "WS", ep.url, "error", err.Error())
+			ep.fail()
+			continue
+		}
+
+		oc, err := os.bindContract(client)
+		if err != nil {
+			os.logger.Warn("cannot bind oracle contract", "WS", ep.url, "error", err.Error())
+			client.Close()
+			ep.fail()
+			continue
+		}
+
+		legacy, legacyContract := os.client, os.oracleContract
+		os.client, os.oracleContract = client, oc
+		if err = os.syncStates(); err != nil && err != types.ErrNoSymbolsObserved {
+			os.logger.Warn("cannot synchronize states from L1 endpoint", "WS", ep.url, "error", err.Error())
+			os.unsubscribe()
+			os.client, os.oracleContract = legacy, legacyContract
+			client.Close()
+			ep.fail()
+			continue
+		}
+
+		if legacy != nil {
+			legacy.Close()
+		}
+		if ep != os.l1Endpoints.current {
+			os.logger.Info("failed over to L1 endpoint", "from", os.l1Endpoints.current.url, "to", ep.url, "score", ep.score)
+		}
+		os.l1Endpoints.current = ep
+		os.l1Endpoints.lastHeight, os.l1Endpoints.lastHeightAt = 0, time.Now()
+		os.l1WSUrl = ep.url
+		return nil
+	}
+	return types.ErrNoHealthyEndpoint
+}
+
+func (os *OracleServer) unsubscribe() {
+	if os.subRoundEvent != nil {
+		os.subRoundEvent.Unsubscribe()
+	}
+	if os.subSymbolsEvent != nil {
+		os.subSymbolsEvent.Unsubscribe()
+	}
+	if os.subVotedEvent != nil {
+		os.subVotedEvent.Unsubscribe()
+	}
+}
@@ -0,0 +1,106 @@
+package oracleserver
+
+import (
+	metricsserver "autonity-oracle/metrics_server"
+	"context"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"math/big"
+	"time"
+)
+
+var (
+	NonceTimeout    = 5 * time.Second // the max time to query the pending nonce or to send a cancellation.
+	CancellationGas = uint64(21000)   // the gas of a zero-value self-transfer.
+)
+
+// nonceManager tracks the nonce assigned locally to the transactions of the oracle server account. The next transaction
+// always takes the pending nonce of the L1 node, and the local nonce is compared with it on each assignment to detect
+// the changes made out of the oracle server: a higher pending nonce means the account was used by another sender, for
+// example a manual transaction of the operator, while a lower pending nonce means there is a gap left by the
+// transactions dropped from the tx pool. The gap is filled by the next transaction, the dropped ones are not re-sent as
+// they carry the votes of the past rounds.
+type nonceManager struct {
+	next   uint64 // the nonce following the last sent one, it is compared with the pending nonce of L1.
+	synced bool
+}
+
+// assignNonce returns the pending nonce of L1 for the next transaction of the oracle server account, the local nonce is
+// only advanced once the transaction is sent, see nonceSent.
+func (os *OracleServer) assignNonce() (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), NonceTimeout)
+	defer cancel()
+	pending, err := os.client.PendingNonceAt(ctx, os.signer.Address())
+	if err != nil {
+		os.logger.Error("get pending nonce", "error", err.Error())
+		return 0, err
+	}
+
+	n := &os.nonces
+	switch {
+	case !n.synced:
+		os.logger.Debug("nonce synced with L1", "nonce", pending)
+	case pending > n.next:
+		os.logger.Info("nonce advanced by another sender of the account", "local", n.next, "pending", pending)
+	case pending < n.next:
+		os.logger.Warn("nonce gap detected, the dropped transactions are superseded from the pending nonce",
+			"local", n.next, "pending", pending)
+		metrics.GetOrRegisterCounter(metricsserver.NonceGapCounter, nil).Inc(1)
+	}
+	n.next, n.synced = pending, true
+	return pending, nil
+}
+
+// nonceSent consumes the nonce of a sent transaction.
+func (os *OracleServer) nonceSent(nonce uint64) {
+	if nonce+1 > os.nonces.next {
+		os.nonces.next = nonce + 1
+	}
+}
+
+// nonceConsumed reports whether the nonce is consumed by a mined transaction of the oracle server account.
+func (os *OracleServer) nonceConsumed(nonce uint64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), NonceTimeout)
+	defer cancel()
+	mined, err := os.client.NonceAt(ctx, os.signer.Address(), nil)
+	if err != nil {
+		return false, err
+	}
+	return mined > nonce, nil
+}
+
+// cancelNonce replaces a stuck transaction with a zero-value self-transfer of the same nonce and bumped gas prices,
+// thus the nonce is consumed quickly, and it does not hold the next round's vote in the tx pool.
+func (os *OracleServer) cancelNonce(stuck *tp.Transaction) (*tp.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), NonceTimeout)
+	defer cancel()
+	chainID, err := os.client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	address := os.signer.Address()
+	tip := bumpGasPrice(stuck.GasTipCap())
+	feeCap := bumpGasPrice(stuck.GasFeeCap())
+	if feeCap.Cmp(tip) < 0 {
+		feeCap = new(big.Int).Set(tip)
+	}
+	tx, err := os.signer.SignTx(tp.NewTx(&tp.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     stuck.Nonce(),
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       CancellationGas,
+		To:        &address,
+		Value:     big.NewInt(0),
+	}), chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	os.nonceSent(tx.Nonce())
+	return tx, nil
+}
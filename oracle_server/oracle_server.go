@@ -1,20 +1,26 @@
 package oracleserver
 
 import (
+	"autonity-oracle/aggregator"
 	"autonity-oracle/config"
 	contract "autonity-oracle/contract_binder/contract"
+	feestrategy "autonity-oracle/fee_strategy"
 	"autonity-oracle/helpers"
+	metricsserver "autonity-oracle/metrics_server"
+	"autonity-oracle/notifier"
 	pWrapper "autonity-oracle/plugin_wrapper"
+	roundstore "autonity-oracle/round_store"
+	"autonity-oracle/signer"
 	"autonity-oracle/types"
 	"context"
 	"crypto/rand"
-	"encoding/json"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	tp "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/hashicorp/go-hclog"
 	"github.com/modern-go/reflect2"
 	"github.com/shopspring/decimal"
@@ -30,7 +36,7 @@ var (
 	OneSecInterval   = 1 * time.Second  // 1s ticker job to check if we need to do pre-sampling.
 	PreSamplingRange = 5                // pre-sampling starts in 5 blocks in advance.
 	SaltRange        = new(big.Int).SetUint64(math.MaxInt64)
-	AlertBalance     = new(big.Int).SetUint64(2000000000000) // 2000 Gwei, 0.000002 Ether
+	FeeTimeout       = 5 * time.Second // the max time to pick the gas fees of a vote.
 )
 
 // OracleServer coordinates the plugin discovery, the data sampling, and do the health checking with L1 connectivity.
@@ -45,12 +51,16 @@ type OracleServer struct {
 	symbols   []string                           // the symbols for data fetching in oracle service.
 
 	keyRequiredPlugins map[string]struct{} // saving those plugins which require a key granted by data provider
+	verifyPlugins      bool                // refuse to start the plugins without a checksum in the plugin config.
+	unloadedPlugins    map[string]struct{} // the plugins unloaded via the admin API, they are skipped by the discovery.
 
 	// the reporting staffs
 	dialer         types.Dialer
 	oracleContract contract.ContractAPI
 	client         types.Blockchain
-	l1WSUrl        string
+	l1WSUrl        string                                                      // the endpoint currently connected.
+	l1Endpoints    *l1Endpoints                                                // the endpoints to fail over between.
+	bindContract   func(client types.Blockchain) (contract.ContractAPI, error) // binds the oracle contract on a new connection.
 
 	curRound        uint64 //round ID.
 	votePeriod      uint64 //vote period.
@@ -59,23 +69,47 @@ type OracleServer struct {
 
 	protocolSymbols []string //symbols required for the voting on the oracle contract protocol.
 	pricePrecision  decimal.Decimal
+	aggregators     *aggregator.Aggregators // the aggregation strategies by symbols.
+	deriver         *aggregator.Deriver     // derives the prices of the symbols which are not provided by any plugin.
 	roundData       map[uint64]*types.RoundData
-	key             *keystore.Key
+	roundStore      *roundstore.RoundStore // persists the round data, thus the commitment can be revealed after a restart.
+	signer          types.Signer           // signs the vote transactions of the oracle server account.
 
 	pluginConfFile string
 
-	gasTipCap uint64
+	feeStrategy types.FeeStrategy // picks the gas limit, the tip and the fee cap of the vote transactions.
 
 	chRoundEvent  chan *contract.OracleNewRound
 	subRoundEvent event.Subscription
 
 	chSymbolsEvent  chan *contract.OracleNewSymbols
 	subSymbolsEvent event.Subscription
-	lastSampledTS   int64
+
+	chVotedEvent  chan *contract.OracleVoted
+	subVotedEvent event.Subscription
+	lastSampledTS int64
 
 	sampleEventFee event.Feed
 	loggingLevel   hclog.Level
-	lostSync       bool // set to true if the connectivity with L1 Autonity network is dropped during runtime.
+	lostSync       bool   // set to true if the connectivity with L1 Autonity network is dropped during runtime.
+	lostRound      uint64 // the last round seen before the connectivity is lost, the rounds after it are backfilled.
+	lostHeight     uint64 // the start height of the last round seen before the connectivity is lost.
+	handledRound   uint64 // the last round rotation handled, either from the NewRound event or from the backfill.
+
+	pendingVote   *pendingVote   // the last vote transaction which is waiting for its receipt.
+	nonces        nonceManager   // the nonce assigned locally to the transactions of the oracle server account.
+	submittedVote *submittedVote // the last vote which is waiting for the confirmation of the voted event.
+
+	chAdminCall chan func() // the calls of the admin API, they are executed in the event loop of the oracle server.
+
+	dryRun        bool                        // compute the votes without submitting them.
+	shadowRounds  map[uint64]*types.RoundData // the votes computed in the dry-run mode, they are not persisted.
+	shadowReports []types.DeviationReport     // the deviations of the dry-run votes from the on-chain prices.
+
+	accuracy accuracyScorer // the accuracy of the revealed prices against the on-chain prices.
+
+	balanceMonitor *balanceMonitor    // estimates the runway of the oracle server account.
+	notifier       *notifier.Notifier // delivers the alerts to the sinks of the operators.
 }
 
 func NewOracleServer(conf *types.OracleServiceConfig, dialer types.Dialer, client types.Blockchain,
@@ -85,13 +119,19 @@ func NewOracleServer(conf *types.OracleServiceConfig, dialer types.Dialer, clien
 		client:             client,
 		oracleContract:     oc,
 		l1WSUrl:            conf.AutonityWSUrl,
+		l1Endpoints:        newL1Endpoints(conf.AutonityWSUrls, conf.AutonityWSUrl),
+		bindContract:       bindOracle,
 		roundData:          make(map[uint64]*types.RoundData),
-		key:                conf.Key,
-		gasTipCap:          conf.GasTipCap,
 		pluginConfFile:     conf.PluginConfFile,
+		verifyPlugins:      conf.PluginVerify,
 		pluginDIR:          conf.PluginDIR,
 		pluginSet:          make(map[string]*pWrapper.PluginWrapper),
 		keyRequiredPlugins: make(map[string]struct{}),
+		unloadedPlugins:    make(map[string]struct{}),
+		chAdminCall:        make(chan func()),
+		dryRun:             conf.DryRun,
+		shadowRounds:       make(map[uint64]*types.RoundData),
+		balanceMonitor:     newBalanceMonitor(conf.BalanceAlert, conf.BalanceRunway),
 		doneCh:             make(chan struct{}),
 		regularTicker:      time.NewTicker(TenSecsInterval),
 		psTicker:           time.NewTicker(OneSecInterval),
@@ -103,6 +143,60 @@ func NewOracleServer(conf *types.OracleServiceConfig, dialer types.Dialer, clien
 		Output: o.Stdout,
 		Level:  conf.LoggingLevel,
 	})
+	os.notifier = notifier.NewNotifier(conf.LoggingLevel, notifier.NewSinks(conf, os.logger)...)
+	os.notifier.Start()
+
+	// reload the round data persisted before a restart, thus the last round's commitment can still be revealed.
+	store, err := roundstore.NewRoundStore(conf.DataDIR)
+	if err != nil {
+		os.logger.Error("cannot open round data store", "error", err.Error(), "data-dir", conf.DataDIR)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
+	os.roundStore = store
+
+	os.roundData, err = store.Load()
+	if err != nil {
+		os.logger.Error("cannot load round data", "error", err.Error(), "data-dir", conf.DataDIR)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
+	os.logger.Info("loaded round data from store", "rounds", len(os.roundData), "data-dir", conf.DataDIR)
+
+	// load the aggregation strategies of symbols, the median is applied to all symbols if they are not configured.
+	aggConf, err := config.LoadAggregationConfig(conf.AggregationConf)
+	if err != nil {
+		os.logger.Error("cannot load aggregation configuration", "error", err.Error(), "path", conf.AggregationConf)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
+	os.aggregators, err = aggregator.NewAggregators(aggConf)
+	if err != nil {
+		os.logger.Error("invalid aggregation configuration", "error", err.Error(), "path", conf.AggregationConf)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
+	os.deriver, err = aggregator.NewDeriver(aggConf.Derivation)
+	if err != nil {
+		os.logger.Error("invalid derivation rules", "error", err.Error(), "path", conf.AggregationConf)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
+
+	// the signer is set up before the plugins, thus the plugins are not started with a broken signer.
+	os.signer, err = signer.NewSigner(conf)
+	if err != nil {
+		os.logger.Error("cannot set up signer", "error", err.Error(), "signer", conf.SignerType, "url", conf.SignerURL)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
+
+	os.feeStrategy, err = feestrategy.NewStrategy(conf, os.logger)
+	if err != nil {
+		os.logger.Error("cannot set up gas fee strategy", "error", err.Error(), "strategy", conf.GasStrategy)
+		helpers.PrintUsage()
+		o.Exit(1)
+	}
 
 	// load plugin configs before start them.
 	plugConfs, err := config.LoadPluginsConfig(conf.PluginConfFile)
@@ -126,7 +220,10 @@ func NewOracleServer(conf *types.OracleServiceConfig, dialer types.Dialer, clien
 		os.loadNewPlugin(f, pConf)
 	}
 
-	os.logger.Info("running oracle contract listener at", "WS", conf.AutonityWSUrl, "ID", conf.Key.Address.String())
+	if os.dryRun {
+		os.logger.Warn("running in the dry-run mode, the votes are not submitted")
+	}
+	os.logger.Info("running oracle contract listener at", "WS", conf.AutonityWSUrl, "ID", os.signer.Address().String())
 	err = os.syncStates()
 	if err != nil {
 		// stop the client on start up once the remote endpoint of autonity L1 network is not ready.
@@ -139,17 +236,14 @@ func NewOracleServer(conf *types.OracleServiceConfig, dialer types.Dialer, clien
 }
 
 func (os *OracleServer) syncStates() error {
-	var err error
-	// get initial states from on-chain oracle contract.
-	os.curRound, os.protocolSymbols, os.pricePrecision, os.votePeriod, err = os.initStates()
-	if err != nil {
+	// get initial states from on-chain oracle contract, they are applied only after the events are subscribed, thus a
+	// failed synchronization with a new L1 endpoint does not leave the states half overwritten.
+	round, symbols, precision, votePeriod, err := os.initStates()
+	if err != nil && err != types.ErrNoSymbolsObserved {
 		os.logger.Error("synchronize oracle contract state", "error", err.Error())
 		return err
 	}
 
-	os.logger.Info("syncStates", "CurrentRound", os.curRound, "Num of AvailableSymbols", len(os.protocolSymbols), "CurrentSymbols", os.protocolSymbols)
-	os.UpdateSymbols(os.protocolSymbols)
-
 	// subscribe on-chain round rotation event
 	os.chRoundEvent = make(chan *contract.OracleNewRound)
 	os.subRoundEvent, err = os.oracleContract.WatchNewRound(new(bind.WatchOpts), os.chRoundEvent)
@@ -166,6 +260,22 @@ func (os *OracleServer) syncStates() error {
 		return err
 	}
 
+	// subscribe on-chain voted event of the oracle server account to confirm the votes.
+	os.chVotedEvent = make(chan *contract.OracleVoted)
+	os.subVotedEvent, err = os.oracleContract.WatchVoted(new(bind.WatchOpts), os.chVotedEvent, []common.Address{os.signer.Address()})
+	if err != nil {
+		os.logger.Error("failed to subscribe voted event", "error", err.Error())
+		return err
+	}
+
+	os.curRound, os.protocolSymbols, os.pricePrecision, os.votePeriod = round, symbols, precision, votePeriod
+	os.logger.Info("syncStates", "CurrentRound", os.curRound, "Num of AvailableSymbols", len(os.protocolSymbols), "CurrentSymbols", os.protocolSymbols)
+	metrics.GetOrRegisterGauge(metricsserver.RoundGauge, nil).Update(int64(os.curRound))
+	os.UpdateSymbols(os.protocolSymbols)
+
+	if len(symbols) == 0 {
+		return types.ErrNoSymbolsObserved
+	}
 	return nil
 }
 
@@ -194,7 +304,7 @@ func (os *OracleServer) initStates() (uint64, []string, decimal.Decimal, uint64,
 	votePeriod, err := os.oracleContract.GetVotePeriod(nil)
 	if err != nil {
 		os.logger.Error("get vote period", "error", err.Error())
-		return 0, nil, precision, 0, nil
+		return 0, nil, precision, 0, err
 	}
 
 	if len(symbols) == 0 {
@@ -219,35 +329,58 @@ func (os *OracleServer) gcRoundData() {
 				delete(os.roundData, k)
 			}
 		}
+
+		if err := os.roundStore.Prune(offset); err != nil {
+			os.logger.Error("prune round data store", "error", err.Error())
+		}
 	}
 }
 
+// handleConnectivityError marks the connectivity with the L1 node as lost, it is handled once until the connectivity is
+// restored, since a drop of the WS connection ends all the event subscriptions at once.
 func (os *OracleServer) handleConnectivityError() {
+	if os.lostSync {
+		return
+	}
+	os.notifyL1Lost()
 	os.lostSync = true
+	os.lostRound, os.lostHeight = os.curRound, os.curSampleHeight
+	os.l1Endpoints.current.fail()
+	metrics.GetOrRegisterCounter(metricsserver.ReconnectionCounter, nil).Inc(1)
 }
 
 func (os *OracleServer) checkHealth() {
 	if os.lostSync {
-		err := os.syncStates()
-		if err != nil && err != types.ErrNoSymbolsObserved {
+		if err := os.failover(); err != nil {
 			os.logger.Info("rebuilding WS connectivity with Autonity L1 node", "error", err)
 			return
 		}
 		os.lostSync = false
+		os.notifyL1Restored()
+		os.backfillRounds(os.lostRound, os.lostHeight)
 		return
 	}
 
 	h, err := os.client.BlockNumber(context.Background())
 	if err != nil {
-		os.logger.Error("get block number", "error", err.Error())
+		os.logger.Error("get block number", "error", err.Error(), "WS", os.l1WSUrl)
+		os.handleConnectivityError()
+		return
+	}
+
+	if !os.l1Endpoints.observe(h, time.Now()) {
+		os.logger.Warn("L1 node is stalled", "height", h, "WS", os.l1WSUrl)
+		os.handleConnectivityError()
 		return
 	}
 
 	r, err := os.oracleContract.GetRound(nil)
 	if err != nil {
-		os.logger.Error("get round", "error", err.Error())
+		os.logger.Error("get round", "error", err.Error(), "WS", os.l1WSUrl)
+		os.handleConnectivityError()
 		return
 	}
+	os.l1Endpoints.current.succeed()
 	os.logger.Debug("checking heart beat", "current height", h, "current round", r.Uint64())
 }
 
@@ -259,42 +392,13 @@ func (os *OracleServer) isVoter() (bool, error) {
 	}
 
 	for _, c := range voters {
-		if c == os.key.Address {
+		if c == os.signer.Address() {
 			return true, nil
 		}
 	}
 	return false, nil
 }
 
-func (os *OracleServer) printLatestRoundData(newRound uint64) {
-	for _, s := range os.protocolSymbols {
-		rd, err := os.oracleContract.GetRoundData(nil, new(big.Int).SetUint64(newRound-1), s)
-		if err != nil {
-			os.logger.Error("get round data", "error", err.Error())
-			return
-		}
-
-		os.logger.Debug("get round price", "round", newRound-1, "symbol", s, "Price",
-			rd.Price.String(), "status", rd.Status.String())
-	}
-
-	for _, s := range os.protocolSymbols {
-		rd, err := os.oracleContract.LatestRoundData(nil, s)
-		if err != nil {
-			os.logger.Error("get latest round price", "error", err.Error())
-			return
-		}
-
-		price, err := decimal.NewFromString(rd.Price.String())
-		if err != nil {
-			continue
-		}
-
-		os.logger.Debug("latest round price", "round", rd.Round.Uint64(), "symbol", s, "price",
-			price.Div(os.pricePrecision).String(), "status", rd.Status.String())
-	}
-}
-
 func (os *OracleServer) handlePreSampling(preSampleTS int64) error {
 	// taking the 1st round and the round after a node recover from a disaster as a special case, to skip the
 	// pre-sampling. In this special case, the regular 10s samples will be used for data reporting.
@@ -316,11 +420,45 @@ func (os *OracleServer) handlePreSampling(preSampleTS int64) error {
 	// do the data pre-sampling.
 	os.logger.Debug("data pre-sampling", "on height", curHeight, "TS", preSampleTS)
 	os.samplePrice(os.symbols, preSampleTS)
+	metrics.GetOrRegisterCounter(metricsserver.PreSamplingCounter, nil).Inc(1)
+	metrics.GetOrRegisterGauge(metricsserver.PreSamplingHeightGauge, nil).Update(int64(curHeight))
 
 	return nil
 }
 
+// applyNewRound saves the round rotation info to coordinate the pre-sampling.
+func (os *OracleServer) handleNewRoundEvent(rEvent *contract.OracleNewRound) {
+	// a round rotated right after a reconnection is seen by both the backfill and the new subscription, it is voted once.
+	if rEvent.Round.Uint64() <= os.handledRound {
+		os.logger.Debug("skip the round which is handled already", "round", rEvent.Round.Uint64())
+		return
+	}
+
+	os.logger.Info("handle new round", "round", rEvent.Round.Uint64(), "required sampling TS",
+		rEvent.Timestamp.Uint64(), "height", rEvent.Height.Uint64(), "round period", rEvent.VotePeriod.Uint64())
+
+	os.applyNewRound(rEvent)
+	if err := os.handleRoundVote(); err != nil {
+		return
+	}
+	os.gcDataSamples()
+	// after vote finished, gc useless symbols by protocol required symbols.
+	os.symbols = os.protocolSymbols
+}
+
+func (os *OracleServer) applyNewRound(rEvent *contract.OracleNewRound) {
+	os.curRound = rEvent.Round.Uint64()
+	os.handledRound = os.curRound
+	metrics.GetOrRegisterGauge(metricsserver.RoundGauge, nil).Update(int64(os.curRound))
+	os.votePeriod = rEvent.VotePeriod.Uint64()
+	os.curSampleHeight = rEvent.Height.Uint64()
+	os.curSampleTS = rEvent.Timestamp.Uint64()
+}
+
 func (os *OracleServer) handleRoundVote() error {
+	// the vote of the last round should be confirmed before the round rotation.
+	os.reconcileVote()
+
 	// if the autonity node is on peer synchronization state, just skip the reporting.
 	syncing, err := os.client.SyncProgress(context.Background())
 	if err != nil {
@@ -340,7 +478,15 @@ func (os *OracleServer) handleRoundVote() error {
 		return err
 	}
 
-	os.printLatestRoundData(os.curRound)
+	os.scoreRound(os.curRound)
+
+	// in the dry-run mode, the votes are computed without being submitted, no matter the client is a voter or not.
+	if os.dryRun {
+		return os.shadowVote(os.curRound)
+	}
+
+	// the stuck vote of the last round is cancelled before this round's vote, thus it does not hold the new vote.
+	os.checkPendingVote()
 
 	// if client is not a voter, just skip reporting.
 	isVoter, err := os.isVoter()
@@ -377,29 +523,35 @@ func (os *OracleServer) reportWithCommitment(newRound uint64, lastRoundData *typ
 		return err
 	}
 
+	// persist current round data before the commitment is sent, thus its salt survives a crash right after the vote for
+	// the reveal in the next round. A failure of persistence is not fatal, the round data is still kept in memory.
+	if err = os.roundStore.Save(curRoundData); err != nil {
+		os.logger.Error("cannot persist round data", "round", newRound, "error", err.Error())
+	}
+
 	// prepare the transaction which carry current round's commitment, and last round's data.
 	curRoundData.Tx, err = os.doReport(curRoundData.CommitmentHash, lastRoundData)
+	os.notifyVote(newRound, curRoundData.Tx, err)
 	if err != nil {
 		os.logger.Error("do report", "error", err.Error())
+		metrics.GetOrRegisterCounter(metricsserver.VoteFailedCounter, nil).Inc(1)
+		if dErr := os.roundStore.Delete(newRound); dErr != nil {
+			os.logger.Error("cannot delete round data", "round", newRound, "error", dErr.Error())
+		}
 		return err
 	}
+	metrics.GetOrRegisterCounter(metricsserver.VoteSentCounter, nil).Inc(1)
+	metrics.GetOrRegisterGauge(metricsserver.ReportedRoundGauge, nil).Update(int64(newRound))
 
-	// save current round data.
+	// update the stored round data with the vote transaction.
 	os.roundData[newRound] = curRoundData
-	os.logger.Info("reported last round data and with current round commitment", "TX hash", curRoundData.Tx.Hash(), "Nonce", curRoundData.Tx.Nonce(), "Cost", curRoundData.Tx.Cost())
-
-	// alert in case of balance reach the warning value.
-	balance, err := os.client.BalanceAt(context.Background(), os.key.Address, nil)
-	if err != nil {
-		os.logger.Error("cannot get account balance", "error", err.Error())
-		return err
-	}
-
-	os.logger.Info("oracle server account", "address", os.key.Address, "remaining balance", balance.String())
-	if balance.Cmp(AlertBalance) <= 0 {
-		os.logger.Warn("oracle account has too less balance left for data reporting", "balance", balance.String())
+	if err = os.roundStore.Save(curRoundData); err != nil {
+		os.logger.Error("cannot persist round data", "round", newRound, "error", err.Error())
 	}
+	os.logger.Info("reported last round data and with current round commitment", "TX hash", curRoundData.Tx.Hash(), "Nonce", curRoundData.Tx.Nonce(), "Cost", curRoundData.Tx.Cost())
 
+	// alert in case of the balance or the runway of the account drops below the thresholds.
+	os.checkBalance(curRoundData.Tx)
 	return nil
 }
 
@@ -407,10 +559,13 @@ func (os *OracleServer) reportWithCommitment(newRound uint64, lastRoundData *typ
 func (os *OracleServer) reportWithoutCommitment(lastRoundData *types.RoundData) error {
 
 	tx, err := os.doReport(common.Hash{}, lastRoundData)
+	os.notifyVote(os.curRound, tx, err)
 	if err != nil {
 		os.logger.Error("do report", "error", err.Error())
+		metrics.GetOrRegisterCounter(metricsserver.VoteFailedCounter, nil).Inc(1)
 		return err
 	}
+	metrics.GetOrRegisterCounter(metricsserver.VoteSentCounter, nil).Inc(1)
 	os.logger.Info("reported last round data and without current round commitment", "TX hash", tx.Hash(), "Nonce", tx.Nonce())
 	return nil
 }
@@ -429,42 +584,111 @@ func (os *OracleServer) UpdateSymbols(newSymbols []string) {
 }
 
 func (os *OracleServer) doReport(curRndCommitHash common.Hash, lastRoundData *types.RoundData) (*tp.Transaction, error) {
-	chainID, err := os.client.ChainID(context.Background())
-	if err != nil {
-		os.logger.Error("get chain id", "error", err.Error())
-		return nil, err
-	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(os.key.PrivateKey, chainID)
+	auth, err := os.newTransactor()
 	if err != nil {
-		os.logger.Error("new keyed transactor with chain ID", "error", err)
 		return nil, err
 	}
 
-	auth.Value = big.NewInt(0)
-	auth.GasTipCap = new(big.Int).SetUint64(os.gasTipCap)
-	auth.GasLimit = uint64(3000000)
-
 	// if there is no last round data, then we just submit the curRndCommitHash hash of current round.
 	var votes []*big.Int
+	salt := types.InvalidSalt
 	if lastRoundData == nil {
 		for i := 0; i < len(os.protocolSymbols); i++ {
 			votes = append(votes, types.InvalidPrice)
 		}
-		return os.oracleContract.Vote(auth, new(big.Int).SetBytes(curRndCommitHash.Bytes()), votes, types.InvalidSalt)
+	} else {
+		for _, s := range lastRoundData.Symbols {
+			_, ok := lastRoundData.Prices[s]
+			if !ok {
+				votes = append(votes, types.InvalidPrice)
+			} else {
+				price := lastRoundData.Prices[s].Price.Mul(os.pricePrecision).BigInt()
+				votes = append(votes, price)
+			}
+		}
+		salt = lastRoundData.Salt
 	}
 
-	for _, s := range lastRoundData.Symbols {
-		_, ok := lastRoundData.Prices[s]
-		if !ok {
-			votes = append(votes, types.InvalidPrice)
-		} else {
-			price := lastRoundData.Prices[s].Price.Mul(os.pricePrecision).BigInt()
-			votes = append(votes, price)
-		}
+	commit := new(big.Int).SetBytes(curRndCommitHash.Bytes())
+	if err = os.setVoteFees(auth, commit, votes, salt); err != nil {
+		return nil, err
+	}
+
+	nonce, err := os.assignNonce()
+	if err != nil {
+		return nil, err
+	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+
+	tx, err := os.oracleContract.Vote(auth, commit, votes, salt)
+	if err != nil {
+		return nil, err
+	}
+	os.nonceSent(nonce)
+
+	// wait for the voted event to confirm that the votes landed in the oracle contract.
+	os.expectVoted(os.curRound, votes)
+
+	// track the receipt of the vote, thus a reverted or a stuck vote can be detected before the round ends.
+	os.trackVote(&pendingVote{
+		round:  os.curRound,
+		txs:    []*tp.Transaction{tx},
+		commit: commit,
+		votes:  votes,
+		salt:   salt,
+	})
+	return tx, nil
+}
+
+// newTransactor returns the transact options to issue vote transactions, the gas settings are set by setVoteFees.
+func (os *OracleServer) newTransactor() (*bind.TransactOpts, error) {
+	chainID, err := os.client.ChainID(context.Background())
+	if err != nil {
+		os.logger.Error("get chain id", "error", err.Error())
+		return nil, err
+	}
+
+	address := os.signer.Address()
+	auth := &bind.TransactOpts{
+		From: address,
+		Signer: func(from common.Address, tx *tp.Transaction) (*tp.Transaction, error) {
+			if from != address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return os.signer.SignTx(tx, chainID)
+		},
+		Context: context.Background(),
+	}
+
+	auth.Value = big.NewInt(0)
+	return auth, nil
+}
+
+// setVoteFees sets the gas settings of a vote picked by the gas fee strategy in the vote period of the current round.
+func (os *OracleServer) setVoteFees(auth *bind.TransactOpts, commit *big.Int, votes []*big.Int, salt *big.Int) error {
+	oracleABI, err := contract.OracleMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	data, err := oracleABI.Pack("vote", commit, votes, salt)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FeeTimeout)
+	defer cancel()
+	msg := ethereum.CallMsg{From: auth.From, To: &types.OracleContractAddress, Data: data}
+	fees, err := os.feeStrategy.Fees(ctx, os.client, msg, os.curSampleHeight, os.curSampleHeight+os.votePeriod)
+	if err != nil {
+		os.logger.Error("pick vote gas fees", "error", err.Error())
+		return err
 	}
 
-	return os.oracleContract.Vote(auth, new(big.Int).SetBytes(curRndCommitHash.Bytes()), votes, lastRoundData.Salt)
+	auth.GasLimit = fees.GasLimit
+	auth.GasTipCap = fees.GasTipCap
+	auth.GasFeeCap = fees.GasFeeCap
+	os.logger.Debug("vote gas fees", "gas limit", fees.GasLimit, "tip", fees.GasTipCap, "fee cap", fees.GasFeeCap)
+	return nil
 }
 
 func (os *OracleServer) buildRoundData(round uint64) (*types.RoundData, error) {
@@ -473,13 +697,35 @@ func (os *OracleServer) buildRoundData(round uint64) (*types.RoundData, error) {
 	}
 
 	prices := make(types.PriceBySymbol)
+	sources := make(map[string]types.PriceByPlugin)
+	derivations := make(map[string][]string)
+	var missing []string
 	for _, s := range os.protocolSymbols {
-		p, err := os.aggregatePrice(s, int64(os.curSampleTS))
+		p, samples, err := os.aggregate(s, int64(os.curSampleTS))
 		if err != nil {
-			os.logger.Debug("no data for aggregation", "reason", err.Error(), "symbol", s)
+			// fill the symbol which is not provided by any plugin with the price derived from the other symbols.
+			derived, dErr := os.derive(s, int64(os.curSampleTS))
+			if dErr != nil {
+				os.logger.Debug("no data for aggregation", "reason", err.Error(), "derivation", dErr.Error(), "symbol", s)
+				missing = append(missing, s)
+				continue
+			}
+			prices[s] = types.Price{Timestamp: int64(os.curSampleTS), Price: derived.Price, Symbol: s}
+			derivations[s] = derived.Inputs
+			metricsserver.PriceGauge(s).Update(derived.Price.InexactFloat64())
 			continue
 		}
 		prices[s] = *p
+		metricsserver.PriceGauge(s).Update(p.Price.InexactFloat64())
+
+		sources[s] = make(types.PriceByPlugin)
+		for _, sample := range samples {
+			sources[s][sample.Plugin] = sample.Price.Price
+		}
+	}
+
+	if len(missing) > 0 {
+		os.notifyPriceUnavailable(round, missing)
 	}
 
 	if len(prices) == 0 {
@@ -498,9 +744,11 @@ func (os *OracleServer) buildRoundData(round uint64) (*types.RoundData, error) {
 		Salt:           salt,
 		CommitmentHash: common.Hash{},
 		Prices:         prices,
+		Sources:        sources,
+		Derivations:    derivations,
 	}
 	roundData.CommitmentHash = os.commitmentHash(roundData, os.protocolSymbols)
-	os.logger.Info("assembled data report", "current round", round, "prices", prices, "commitment hash", roundData.CommitmentHash.String())
+	os.logger.Info("assembled data report", "current round", round, "prices", prices, "derivations", derivations, "commitment hash", roundData.CommitmentHash.String())
 	return roundData, nil
 }
 
@@ -516,52 +764,105 @@ func (os *OracleServer) commitmentHash(roundData *types.RoundData, symbols []str
 	// append the salt at the tail of votes
 	source = append(source, common.LeftPadBytes(roundData.Salt.Bytes(), 32)...)
 	// append the sender address at the tail for commitment hash computing as well
-	source = append(source, os.key.Address.Bytes()...)
+	source = append(source, os.signer.Address().Bytes()...)
 	return crypto.Keccak256Hash(source)
 }
 
 func (os *OracleServer) handleNewSymbolsEvent(symbols []string) {
 	// just add symbols to oracle service's symbol pool, thus the oracle service can start to prepare the data.
+	known := len(os.symbols)
 	os.UpdateSymbols(symbols)
+	if added := os.symbols[known:]; len(added) > 0 {
+		os.notifySymbolsChanged(added)
+	}
+}
+
+// derive returns the price of a symbol derived from the aggregated prices of the other symbols by the derivation rules.
+func (os *OracleServer) derive(s string, target int64) (*aggregator.Derivation, error) {
+	return os.deriver.Derive(s, func(input string) (decimal.Decimal, error) {
+		p, err := os.aggregatePrice(input, target)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		return p.Price, nil
+	})
 }
 
 func (os *OracleServer) aggregatePrice(s string, target int64) (*types.Price, error) {
-	var prices []decimal.Decimal
+	price, _, err := os.aggregate(s, target)
+	return price, err
+}
 
-	for _, plugin := range os.pluginSet {
+// aggregate returns the aggregated price of a symbol with the samples of the plugins contributed to it.
+func (os *OracleServer) aggregate(s string, target int64) (*types.Price, []aggregator.Sample, error) {
+	var samples []aggregator.Sample
+
+	for name, plugin := range os.pluginSet {
 		p, err := plugin.GetSample(s, target)
 		if err != nil {
 			continue
 		}
-		prices = append(prices, p.Price)
+		samples = append(samples, aggregator.Sample{Plugin: name, Price: p})
 	}
 
-	if len(prices) == 0 {
-		return nil, types.ErrNoDataRound
+	if len(samples) == 0 {
+		return nil, nil, types.ErrNoDataRound
+	}
+
+	// drop the bad prints of plugins before the aggregation.
+	filter := os.aggregators.FilterOf(s)
+	var last *decimal.Decimal
+	if filter.ChecksJump() {
+		last = os.lastOnChainPrice(s)
+	}
+	samples, excluded := filter.Filter(samples, last)
+	for _, e := range excluded {
+		os.logger.Warn("excluded outlier sample", "symbol", s, "plugin", e.Sample.Plugin, "price", e.Sample.Price.Price,
+			"reason", e.Reason, "reference", e.Reference, "distance", e.Distance)
+		metricsserver.PluginOutlierCounter(e.Sample.Plugin).Inc(1)
 	}
 
 	price := &types.Price{
 		Timestamp: target,
-		Price:     prices[0],
+		Price:     samples[0].Price.Price,
 		Symbol:    s,
 	}
 
-	// we have multiple provider provide prices for this symbol, we have to aggregate it.
-	if len(prices) > 1 {
-		p, err := helpers.Median(prices)
+	// we have multiple provider provide prices for this symbol, we have to aggregate it with the strategy of the symbol.
+	if len(samples) > 1 {
+		p, err := os.aggregators.Of(s).Aggregate(samples)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		price.Price = p
 	}
 
-	return price, nil
+	return price, samples, nil
+}
+
+// lastOnChainPrice returns the last price of the symbol aggregated by the oracle contract, it returns nil if there is
+// no valid price on-chain.
+func (os *OracleServer) lastOnChainPrice(s string) *decimal.Decimal {
+	rd, err := os.oracleContract.LatestRoundData(nil, s)
+	if err != nil {
+		os.logger.Error("get latest round data", "symbol", s, "error", err.Error())
+		return nil
+	}
+
+	if rd.Status == nil || rd.Status.Uint64() != 0 || rd.Price == nil || rd.Price.Sign() <= 0 || os.pricePrecision.IsZero() {
+		return nil
+	}
+
+	price := decimal.NewFromBigInt(rd.Price, 0).Div(os.pricePrecision)
+	return &price
 }
 
 func (os *OracleServer) samplePrice(symbols []string, ts int64) {
 	if os.lastSampledTS == ts {
 		return
 	}
+	// the inputs of the derived symbols are sampled too.
+	symbols = os.deriver.Extend(symbols)
 	cpSymbols := make([]string, len(symbols))
 	copy(cpSymbols, symbols)
 	e := &types.SampleEvent{
@@ -593,6 +894,14 @@ func (os *OracleServer) Start() {
 				os.handleConnectivityError()
 				os.subRoundEvent.Unsubscribe()
 			}
+		case err := <-os.subVotedEvent.Err():
+			if err != nil {
+				os.logger.Info("subscription error of voted event", err)
+				os.handleConnectivityError()
+				os.subVotedEvent.Unsubscribe()
+			}
+		case voted := <-os.chVotedEvent:
+			os.handleVotedEvent(voted)
 		case <-os.psTicker.C:
 			preSampleTS := time.Now().Unix()
 			err := os.handlePreSampling(preSampleTS)
@@ -600,23 +909,11 @@ func (os *OracleServer) Start() {
 				os.logger.Error("handle pre-sampling", "error", err.Error())
 			}
 			os.lastSampledTS = preSampleTS
+			os.checkPendingVote()
 		case rEvent := <-os.chRoundEvent:
-			os.logger.Info("handle new round", "round", rEvent.Round.Uint64(), "required sampling TS",
-				rEvent.Timestamp.Uint64(), "height", rEvent.Height.Uint64(), "round period", rEvent.VotePeriod.Uint64())
-
-			// save the round rotation info to coordinate the pre-sampling.
-			os.curRound = rEvent.Round.Uint64()
-			os.votePeriod = rEvent.VotePeriod.Uint64()
-			os.curSampleHeight = rEvent.Height.Uint64()
-			os.curSampleTS = rEvent.Timestamp.Uint64()
-
-			err := os.handleRoundVote()
-			if err != nil {
-				continue
-			}
-			os.gcDataSamples()
-			// after vote finished, gc useless symbols by protocol required symbols.
-			os.symbols = os.protocolSymbols
+			os.handleNewRoundEvent(rEvent)
+		case call := <-os.chAdminCall:
+			call()
 		case symbols := <-os.chSymbolsEvent:
 			os.logger.Info("handle new symbols", "new symbols", symbols.Symbols, "activate at round", symbols.Round)
 			os.handleNewSymbolsEvent(symbols.Symbols)
@@ -637,6 +934,8 @@ func (os *OracleServer) Stop() {
 	os.client.Close()
 	os.subRoundEvent.Unsubscribe()
 	os.subSymbolsEvent.Unsubscribe()
+	os.subVotedEvent.Unsubscribe()
+	os.notifier.Stop()
 
 	os.doneCh <- struct{}{}
 	for _, c := range os.pluginSet {
@@ -662,6 +961,11 @@ func (os *OracleServer) PluginRuntimeDiscovery() {
 		f := file
 		pConf := plugConfs[f.Name()]
 
+		// skip those plugins which are unloaded by the operator until they are reloaded via the admin API.
+		if _, ok := os.unloadedPlugins[f.Name()]; ok {
+			continue
+		}
+
 		// skip to set up plugins until there is a service key is presented at plugin-confs.yml
 		if _, ok := os.keyRequiredPlugins[f.Name()]; ok && pConf.Key == "" {
 			continue
@@ -683,23 +987,45 @@ func (os *OracleServer) loadNewPlugin(f fs.FileInfo, plugConf types.PluginConfig
 		return
 	}
 
-	if f.ModTime().After(plugin.StartTime()) || plugin.Exited() {
-		os.logger.Info("replacing legacy plugin with new one: ", f.Name(), f.Mode().String())
-		// stop the legacy plugin
-		plugin.Close()
-		delete(os.pluginSet, f.Name())
+	// the plugin loads its configuration on startup, thus a change of the configuration is applied by a restart.
+	var changes []string
+	if conf := plugin.Config(); conf != nil {
+		changes = conf.Diff(&plugConf)
+	}
+
+	if f.ModTime().After(plugin.StartTime()) || plugin.Exited() || len(changes) > 0 {
+		// verify the new binary before the legacy plugin is stopped, thus a tampered binary cannot take over a running one.
+		if err := os.verifyPlugin(f.Name(), &plugConf); err != nil {
+			return
+		}
+
+		if plugin.Exited() {
+			os.notifyPlugin(notifier.EventPluginCrashed, notifier.SeverityWarning, "plugin exited", f.Name())
+		}
 
+		if len(changes) > 0 {
+			os.logger.Info("plugin configuration changed", "name", f.Name(), "changes", changes)
+		}
+		os.logger.Info("replacing legacy plugin with new one: ", f.Name(), f.Mode().String())
+		// the legacy plugin keeps serving until the new one is set up, unless it is exited.
 		pluginWrapper, err := os.setupNewPlugin(f.Name(), &plugConf)
 		if err != nil {
+			if plugin.Exited() {
+				plugin.Close()
+				delete(os.pluginSet, f.Name())
+			}
 			return
 		}
+
+		// stop the legacy plugin
+		plugin.Close()
 		os.pluginSet[f.Name()] = pluginWrapper
+		os.notifyPlugin(notifier.EventPluginReplaced, notifier.SeverityInfo, "plugin replaced", f.Name())
 	}
 }
 
 func (os *OracleServer) setupNewPlugin(name string, conf *types.PluginConfig) (*pWrapper.PluginWrapper, error) {
-	if err := os.ApplyPluginConf(name, conf); err != nil {
-		os.logger.Error("apply plugin config", "error", err.Error())
+	if err := os.verifyPlugin(name, conf); err != nil {
 		return nil, err
 	}
 
@@ -718,20 +1044,24 @@ func (os *OracleServer) setupNewPlugin(name string, conf *types.PluginConfig) (*
 	return pluginWrapper, nil
 }
 
-func (os *OracleServer) WatchSampleEvent(sink chan<- *types.SampleEvent) event.Subscription {
-	return os.sampleEventFee.Subscribe(sink)
-}
-
-func (os *OracleServer) ApplyPluginConf(name string, plugConf *types.PluginConfig) error {
-	// set the plugin configuration via system env, thus the plugin can load it on startup.
-	conf, err := json.Marshal(plugConf)
-	if err != nil {
-		os.logger.Error("cannot marshal plugin's configuration", "error", err.Error())
-		return err
+// verifyPlugin checks the plugin binary with the checksum set in the plugin config, a plugin without a checksum is
+// refused if the verification of plugins is required.
+func (os *OracleServer) verifyPlugin(name string, conf *types.PluginConfig) error {
+	if conf.Checksum == "" {
+		if os.verifyPlugins {
+			os.logger.Error("refuse to start plugin without checksum", "name", name)
+			return types.ErrMissingChecksum
+		}
+		return nil
 	}
-	if err = o.Setenv(name, string(conf)); err != nil {
-		os.logger.Error("cannot set plugin configuration via system ENV")
+
+	if err := pWrapper.VerifyChecksum(os.pluginDIR, name, conf); err != nil {
+		os.logger.Error("refuse to start plugin with unverified binary", "name", name, "error", err.Error())
 		return err
 	}
 	return nil
 }
+
+func (os *OracleServer) WatchSampleEvent(sink chan<- *types.SampleEvent) event.Subscription {
+	return os.sampleEventFee.Subscribe(sink)
+}
@@ -1,17 +1,29 @@
 package oracleserver
 
 import (
+	"autonity-oracle/aggregator"
 	"autonity-oracle/config"
 	contract "autonity-oracle/contract_binder/contract"
 	cMock "autonity-oracle/contract_binder/contract/mock"
+	feestrategy "autonity-oracle/fee_strategy"
 	"autonity-oracle/helpers"
+	"autonity-oracle/notifier"
+	pWrapper "autonity-oracle/plugin_wrapper"
+	roundstore "autonity-oracle/round_store"
+	"autonity-oracle/signer"
 	"autonity-oracle/types"
 	"autonity-oracle/types/mock"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	tp "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-hclog"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"io/ioutil" //nolint
@@ -32,6 +44,7 @@ func TestOracleServer(t *testing.T) {
 	os.Setenv("KEY.FILE", "../test_data/keystore/UTC--2023-02-27T09-10-19.592765887Z--b749d3d83376276ab4ddef2d9300fb5ce70ebafe") //nolint
 	os.Setenv("PLUGIN.DIR", "../plugins/template_plugin/bin")                                                                    //nolint
 	os.Setenv("PLUGIN.CONF", "../test_data/plugins-conf.yml")                                                                    //nolint
+	os.Setenv("DATA.DIR", t.TempDir())                                                                                           //nolint
 	defer os.Clearenv()
 	conf := config.MakeConfig()
 
@@ -47,6 +60,7 @@ func TestOracleServer(t *testing.T) {
 		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
 		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
 		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		l1Mock := mock.NewMockBlockchain(ctrl)
 
 		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
@@ -71,6 +85,7 @@ func TestOracleServer(t *testing.T) {
 		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
 		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
 		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		l1Mock := mock.NewMockBlockchain(ctrl)
 		l1Mock.EXPECT().BlockNumber(gomock.Any()).AnyTimes().Return(chainHeight, nil)
 
@@ -125,20 +140,31 @@ func TestOracleServer(t *testing.T) {
 		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
 		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
 		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		contractMock.EXPECT().GetVoters(nil).Return(voters, nil)
 		contractMock.EXPECT().GetRoundData(nil, new(big.Int).SetUint64(1), gomock.Any()).AnyTimes().Return(price, nil)
 		contractMock.EXPECT().LatestRoundData(nil, gomock.Any()).AnyTimes().Return(price, nil)
 
 		txdata := &tp.DynamicFeeTx{ChainID: new(big.Int).SetUint64(1000), Nonce: 1}
 		tx := tp.NewTx(txdata)
-		contractMock.EXPECT().Vote(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(tx, nil)
+		var srv *OracleServer
+		contractMock.EXPECT().Vote(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(opts *bind.TransactOpts, commit *big.Int, votes []*big.Int, salt *big.Int) (*tp.Transaction, error) {
+				// the salt of the commitment is persisted before the vote is sent.
+				persisted, err := srv.roundStore.Load()
+				require.NoError(t, err)
+				require.Equal(t, commit, new(big.Int).SetBytes(persisted[srv.curRound].CommitmentHash.Bytes()))
+				require.NotNil(t, persisted[srv.curRound].Salt)
+				return tx, nil
+			})
 
 		l1Mock := mock.NewMockBlockchain(ctrl)
 		l1Mock.EXPECT().BlockNumber(gomock.Any()).AnyTimes().Return(chainHeight, nil)
 		l1Mock.EXPECT().SyncProgress(gomock.Any()).Return(nil, nil)
 		l1Mock.EXPECT().ChainID(gomock.Any()).Return(new(big.Int).SetUint64(1000), nil)
-		l1Mock.EXPECT().BalanceAt(gomock.Any(), gomock.Any(), gomock.Any()).Return(AlertBalance, nil)
-		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), conf.Key.Address).Return(uint64(1), nil)
+		l1Mock.EXPECT().BalanceAt(gomock.Any(), gomock.Any(), gomock.Any()).Return(new(big.Int).SetUint64(config.DefaultBalanceAlert), nil)
+		srv = NewOracleServer(conf, dialerMock, l1Mock, contractMock)
 
 		// prepare last round data.
 		prices := make(types.PriceBySymbol)
@@ -183,6 +209,26 @@ func TestOracleServer(t *testing.T) {
 		require.Equal(t, config.DefaultSymbols, srv.roundData[srv.curRound].Symbols)
 		require.Equal(t, srv.commitmentHash(srv.roundData[srv.curRound], config.DefaultSymbols), srv.roundData[srv.curRound].CommitmentHash)
 
+		// the round data should be persisted for the reveal after a restart.
+		persisted, err := srv.roundStore.Load()
+		require.NoError(t, err)
+		require.Equal(t, srv.roundData[srv.curRound].CommitmentHash, persisted[srv.curRound].CommitmentHash)
+		require.Equal(t, 0, srv.roundData[srv.curRound].Salt.Cmp(persisted[srv.curRound].Salt))
+		require.Equal(t, tx.Hash(), persisted[srv.curRound].Tx.Hash())
+
+		// the round data of a failed vote is removed from the store.
+		l1Mock.EXPECT().ChainID(gomock.Any()).Return(new(big.Int).SetUint64(1000), nil)
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), conf.Key.Address).Return(uint64(2), nil)
+		contractMock.EXPECT().Vote(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("rejected"))
+		err = srv.reportWithCommitment(srv.curRound+1, srv.roundData[srv.curRound])
+		require.Error(t, err)
+		persisted, err = srv.roundStore.Load()
+		require.NoError(t, err)
+		_, ok := persisted[srv.curRound+1]
+		require.False(t, ok)
+		_, ok = srv.roundData[srv.curRound+1]
+		require.False(t, ok)
+
 		srv.pluginSet["template_plugin"].Close()
 	})
 
@@ -198,6 +244,7 @@ func TestOracleServer(t *testing.T) {
 		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
 		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
 		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		l1Mock := mock.NewMockBlockchain(ctrl)
 
 		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
@@ -226,6 +273,7 @@ func TestOracleServer(t *testing.T) {
 		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
 		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
 		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		l1Mock := mock.NewMockBlockchain(ctrl)
 
 		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
@@ -265,6 +313,7 @@ func TestOracleServer(t *testing.T) {
 		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
 		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
 		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
 		l1Mock := mock.NewMockBlockchain(ctrl)
 
 		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
@@ -287,20 +336,1022 @@ func TestOracleServer(t *testing.T) {
 		srv.pluginSet["template_plugin"].Close()
 	})
 
+	t.Run("track vote, replace stuck vote and confirm it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store, err := roundstore.NewRoundStore(t.TempDir())
+		require.NoError(t, err)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			client:          l1Mock,
+			oracleContract:  contractMock,
+			signer:          signer.NewKeystoreSigner(conf.Key),
+			roundData:       make(map[uint64]*types.RoundData),
+			roundStore:      store,
+			curRound:        10,
+			curSampleHeight: 100,
+			votePeriod:      30,
+			feeStrategy:     feestrategy.NewStaticStrategy(1, config.DefaultGasLimitMax),
+		}
+
+		stuckTx := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Nonce: 5, Gas: 3000000,
+			GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000)})
+		srv.roundData[10] = &types.RoundData{RoundID: 10, Tx: stuckTx, Salt: big.NewInt(1)}
+		srv.trackVote(&pendingVote{round: 10, txs: []*tp.Transaction{stuckTx}, commit: big.NewInt(1),
+			salt: big.NewInt(1)})
+
+		replacement := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Nonce: 5, Gas: 3000000,
+			GasTipCap: big.NewInt(125), GasFeeCap: big.NewInt(1250)})
+		l1Mock.EXPECT().TransactionReceipt(gomock.Any(), stuckTx.Hash()).Times(2).Return(nil, ethereum.NotFound)
+		gomock.InOrder(
+			l1Mock.EXPECT().BlockNumber(gomock.Any()).Return(uint64(101), nil),
+			l1Mock.EXPECT().BlockNumber(gomock.Any()).Return(uint64(101+VoteStuckBlocks), nil),
+		)
+		l1Mock.EXPECT().ChainID(gomock.Any()).Return(big.NewInt(1000), nil)
+		contractMock.EXPECT().Vote(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(opts *bind.TransactOpts, commit *big.Int, votes []*big.Int, salt *big.Int) (*tp.Transaction, error) {
+				require.Equal(t, uint64(5), opts.Nonce.Uint64())
+				require.Equal(t, uint64(125), opts.GasTipCap.Uint64())
+				require.Equal(t, uint64(1250), opts.GasFeeCap.Uint64())
+				return replacement, nil
+			})
+
+		// the first check starts the stuck detection, the second one replaces the stuck vote.
+		srv.checkPendingVote()
+		srv.checkPendingVote()
+		require.Equal(t, 1, srv.pendingVote.resubmissions)
+		require.Equal(t, replacement.Hash(), srv.pendingVote.latest().Hash())
+		require.Equal(t, replacement.Hash(), srv.roundData[10].Tx.Hash())
+
+		// the stuck vote is mined rather than its replacement, it is found by its own hash.
+		l1Mock.EXPECT().TransactionReceipt(gomock.Any(), replacement.Hash()).Return(nil, ethereum.NotFound)
+		l1Mock.EXPECT().TransactionReceipt(gomock.Any(), stuckTx.Hash()).Return(&tp.Receipt{
+			Status:      tp.ReceiptStatusSuccessful,
+			TxHash:      stuckTx.Hash(),
+			BlockNumber: big.NewInt(107),
+		}, nil)
+		srv.curRound = 11
+		srv.checkPendingVote()
+		require.Nil(t, srv.pendingVote)
+	})
+
+	t.Run("track vote, expire unconfirmed vote on round rotation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		key := signer.NewKeystoreSigner(conf.Key)
+		srv := &OracleServer{
+			logger:   hclog.NewNullLogger(),
+			client:   l1Mock,
+			signer:   key,
+			curRound: 10,
+		}
+
+		tx := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Nonce: 5, GasTipCap: big.NewInt(100),
+			GasFeeCap: big.NewInt(1000)})
+		srv.trackVote(&pendingVote{round: 10, txs: []*tp.Transaction{tx}})
+		l1Mock.EXPECT().TransactionReceipt(gomock.Any(), tx.Hash()).Return(nil, ethereum.NotFound)
+
+		// the nonce of the expired vote is cancelled by a zero-value self-transfer with bumped gas prices.
+		l1Mock.EXPECT().NonceAt(gomock.Any(), key.Address(), nil).Return(uint64(5), nil)
+		l1Mock.EXPECT().ChainID(gomock.Any()).Return(big.NewInt(1000), nil)
+		l1Mock.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, cancel *tp.Transaction) error {
+				require.Equal(t, uint64(5), cancel.Nonce())
+				require.Equal(t, key.Address(), *cancel.To())
+				require.Equal(t, 0, cancel.Value().Sign())
+				require.Equal(t, CancellationGas, cancel.Gas())
+				require.Equal(t, int64(125), cancel.GasTipCap().Int64())
+				require.Equal(t, int64(1250), cancel.GasFeeCap().Int64())
+				from, err := tp.Sender(tp.LatestSignerForChainID(big.NewInt(1000)), cancel)
+				require.NoError(t, err)
+				require.Equal(t, key.Address(), from)
+				return nil
+			})
+
+		srv.curRound = 11
+		newTx := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Nonce: 6})
+		srv.trackVote(&pendingVote{round: 11, txs: []*tp.Transaction{newTx}})
+		require.Equal(t, newTx.Hash(), srv.pendingVote.latest().Hash())
+		require.Equal(t, uint64(6), srv.nonces.next)
+	})
+
+	t.Run("track vote, skip cancelling a consumed nonce", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		key := signer.NewKeystoreSigner(conf.Key)
+		srv := &OracleServer{
+			logger:   hclog.NewNullLogger(),
+			client:   l1Mock,
+			signer:   key,
+			curRound: 11,
+		}
+
+		tx := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Nonce: 5})
+		srv.pendingVote = &pendingVote{round: 10, txs: []*tp.Transaction{tx}}
+
+		// the vote is mined between the receipt query and the nonce query.
+		gomock.InOrder(
+			l1Mock.EXPECT().TransactionReceipt(gomock.Any(), tx.Hash()).Return(nil, ethereum.NotFound),
+			l1Mock.EXPECT().NonceAt(gomock.Any(), key.Address(), nil).Return(uint64(6), nil),
+			l1Mock.EXPECT().TransactionReceipt(gomock.Any(), tx.Hash()).Return(&tp.Receipt{
+				Status: tp.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: big.NewInt(130)}, nil),
+		)
+		srv.checkPendingVote()
+		require.Nil(t, srv.pendingVote)
+
+		// the nonce is consumed by a transaction of another sender, the vote is expired without a cancellation.
+		srv.pendingVote = &pendingVote{round: 10, txs: []*tp.Transaction{tx}}
+		l1Mock.EXPECT().TransactionReceipt(gomock.Any(), tx.Hash()).Times(2).Return(nil, ethereum.NotFound)
+		l1Mock.EXPECT().NonceAt(gomock.Any(), key.Address(), nil).Return(uint64(6), nil)
+		srv.checkPendingVote()
+		require.Nil(t, srv.pendingVote)
+	})
+
+	t.Run("assign nonce, reconcile with the pending nonce of L1", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		key := signer.NewKeystoreSigner(conf.Key)
+		srv := &OracleServer{
+			logger: hclog.NewNullLogger(),
+			client: l1Mock,
+			signer: key,
+		}
+
+		// the local nonce is synced with L1 on the first assignment.
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), key.Address()).Return(uint64(7), nil)
+		nonce, err := srv.assignNonce()
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), nonce)
+		srv.nonceSent(nonce)
+		require.Equal(t, uint64(8), srv.nonces.next)
+
+		// a manual transaction of the operator advances the pending nonce.
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), key.Address()).Return(uint64(9), nil)
+		nonce, err = srv.assignNonce()
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), nonce)
+		srv.nonceSent(nonce)
+
+		// the last transaction is dropped from the tx pool, the gap is filled by the next transaction.
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), key.Address()).Return(uint64(9), nil)
+		nonce, err = srv.assignNonce()
+		require.NoError(t, err)
+		require.Equal(t, uint64(9), nonce)
+
+		// a failed query does not assign a nonce.
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), key.Address()).Return(uint64(0), fmt.Errorf("connection reset"))
+		_, err = srv.assignNonce()
+		require.Error(t, err)
+	})
+
+	t.Run("aggregate price with the strategy of the symbol", func(t *testing.T) {
+		aggregators, err := aggregator.NewAggregators(&types.AggregationConfig{
+			Symbols: map[string]types.AggregatorConfig{
+				"NTN-USD": {Strategy: aggregator.Weighted, Weights: map[string]float64{"p1": 3, "p2": 1}},
+			},
+		})
+		require.NoError(t, err)
+
+		srv := &OracleServer{
+			logger:      hclog.NewNullLogger(),
+			aggregators: aggregators,
+			pluginSet:   make(map[string]*pWrapper.PluginWrapper),
+		}
+
+		target := time.Now().Unix()
+		for name, price := range map[string]string{"p1": "10", "p2": "14"} {
+			pw := pWrapper.NewPluginWrapper(hclog.NoLevel, name, t.TempDir(), nil, &types.PluginConfig{})
+			for _, s := range []string{"NTN-USD", "EUR-USD"} {
+				pw.AddSample([]types.Price{{Symbol: s, Price: decimal.RequireFromString(price), Timestamp: target}}, target)
+			}
+			srv.pluginSet[name] = pw
+		}
+
+		p, err := srv.aggregatePrice("NTN-USD", target)
+		require.NoError(t, err)
+		require.True(t, p.Price.Equal(decimal.RequireFromString("11")))
+
+		// the median is applied to the symbols without a strategy.
+		p, err = srv.aggregatePrice("EUR-USD", target)
+		require.NoError(t, err)
+		require.True(t, p.Price.Equal(decimal.RequireFromString("12")))
+
+		_, err = srv.aggregatePrice("GBP-USD", target)
+		require.ErrorIs(t, err, types.ErrNoDataRound)
+	})
+
+	t.Run("derive the prices of the symbols not provided by any plugin", func(t *testing.T) {
+		aggregators, err := aggregator.NewAggregators(&types.AggregationConfig{})
+		require.NoError(t, err)
+		deriver, err := aggregator.NewDeriver(types.DerivationConfig{
+			Rules: map[string]string{"NTN-ATN": "NTN-USD / ATN-USD"},
+		})
+		require.NoError(t, err)
+
+		target := time.Now().Unix()
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			aggregators:     aggregators,
+			deriver:         deriver,
+			pluginSet:       make(map[string]*pWrapper.PluginWrapper),
+			protocolSymbols: []string{"NTN-USD", "NTN-ATN"},
+			signer:          signer.NewKeystoreSigner(conf.Key),
+			pricePrecision:  decimal.NewFromInt(precision.Int64()),
+			curSampleTS:     uint64(target),
+		}
+		pw := pWrapper.NewPluginWrapper(hclog.NoLevel, "p1", t.TempDir(), nil, &types.PluginConfig{})
+		for s, price := range map[string]string{"NTN-USD": "10", "ATN-USD": "4"} {
+			pw.AddSample([]types.Price{{Symbol: s, Price: decimal.RequireFromString(price), Timestamp: target}}, target)
+		}
+		srv.pluginSet["p1"] = pw
+
+		// the inputs of the derived symbols are sampled too.
+		require.Equal(t, []string{"NTN-USD", "NTN-ATN", "ATN-USD"}, srv.deriver.Extend(srv.protocolSymbols))
+
+		roundData, err := srv.buildRoundData(100)
+		require.NoError(t, err)
+		require.True(t, roundData.Prices["NTN-ATN"].Price.Equal(decimal.RequireFromString("2.5")))
+		require.Equal(t, []string{"NTN-USD", "ATN-USD"}, roundData.Derivations["NTN-ATN"])
+		require.Equal(t, 1, len(roundData.Derivations))
+		require.Equal(t, 1, len(roundData.Sources["NTN-USD"]))
+	})
+
+	t.Run("aggregate price without the outliers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aggregators, err := aggregator.NewAggregators(&types.AggregationConfig{
+			Outlier: types.OutlierConfig{MaxDeviation: 0.05, MaxJump: 0.1},
+		})
+		require.NoError(t, err)
+
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:         hclog.NewNullLogger(),
+			oracleContract: contractMock,
+			aggregators:    aggregators,
+			pricePrecision: decimal.NewFromInt(precision.Int64()),
+			pluginSet:      make(map[string]*pWrapper.PluginWrapper),
+		}
+
+		target := time.Now().Unix()
+		addPlugin := func(name, price string) {
+			pw := pWrapper.NewPluginWrapper(hclog.NoLevel, name, t.TempDir(), nil, &types.PluginConfig{})
+			pw.AddSample([]types.Price{{Symbol: "EUR-USD", Price: decimal.RequireFromString(price), Timestamp: target}}, target)
+			srv.pluginSet[name] = pw
+		}
+
+		// the inverted rate of currencylayer jumps from the last on-chain price, it is excluded from the 2 samples.
+		addPlugin("forex_openexchange", "1.08")
+		addPlugin("forex_currencylayer", "0.92")
+		contractMock.EXPECT().LatestRoundData(nil, "EUR-USD").Return(contract.IOracleRoundData{
+			Round:     big.NewInt(9),
+			Price:     big.NewInt(10800000),
+			Timestamp: big.NewInt(target),
+			Status:    big.NewInt(0),
+		}, nil)
+		p, err := srv.aggregatePrice("EUR-USD", target)
+		require.NoError(t, err)
+		require.True(t, p.Price.Equal(decimal.RequireFromString("1.08")))
+
+		// with 3 samples, it is excluded by the deviation from the median of the plugins.
+		addPlugin("forex_currencyfreaks", "1.09")
+		contractMock.EXPECT().LatestRoundData(nil, "EUR-USD").Return(contract.IOracleRoundData{}, fmt.Errorf("no data"))
+		p, err = srv.aggregatePrice("EUR-USD", target)
+		require.NoError(t, err)
+		require.True(t, p.Price.Equal(decimal.RequireFromString("1.085")))
+	})
+
+	t.Run("verify plugin checksum before starting or replacing it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		dialerMock := mock.NewMockDialer(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		contractMock.EXPECT().GetRound(nil).Return(currentRound, nil)
+		contractMock.EXPECT().GetSymbols(nil).Return(config.DefaultSymbols, nil)
+		contractMock.EXPECT().GetPrecision(nil).Return(precision, nil)
+		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
+		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
+		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+
+		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
+		require.Equal(t, 1, len(srv.pluginSet))
+		legacy := srv.pluginSet["template_plugin"]
+
+		binary, err := os.ReadFile(srv.pluginDIR + "/template_plugin")
+		require.NoError(t, err)
+		sum := sha256.Sum256(binary)
+		checksum := hex.EncodeToString(sum[:])
+		sum[0]++
+		badChecksum := hex.EncodeToString(sum[:])
+
+		srv.verifyPlugins = true
+		require.ErrorIs(t, srv.verifyPlugin("template_plugin", &types.PluginConfig{}), types.ErrMissingChecksum)
+		require.NoError(t, srv.verifyPlugin("template_plugin", &types.PluginConfig{Checksum: checksum}))
+
+		// the legacy plugin keeps running if the replacement does not match the checksum.
+		require.NoError(t, replacePlugins(srv.pluginDIR))
+		binaries, err := helpers.ListPlugins(srv.pluginDIR)
+		require.NoError(t, err)
+		srv.loadNewPlugin(binaries[0], types.PluginConfig{Checksum: badChecksum})
+		require.Equal(t, legacy, srv.pluginSet["template_plugin"])
+
+		srv.loadNewPlugin(binaries[0], types.PluginConfig{Checksum: checksum})
+		require.NotEqual(t, legacy, srv.pluginSet["template_plugin"])
+		srv.pluginSet["template_plugin"].Close()
+	})
+
+	t.Run("restart plugin on configuration change", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		dialerMock := mock.NewMockDialer(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		contractMock.EXPECT().GetRound(nil).Return(currentRound, nil)
+		contractMock.EXPECT().GetSymbols(nil).Return(config.DefaultSymbols, nil)
+		contractMock.EXPECT().GetPrecision(nil).Return(precision, nil)
+		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
+		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
+		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+
+		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
+		require.Equal(t, 1, len(srv.pluginSet))
+		legacy := srv.pluginSet["template_plugin"]
+
+		binaries, err := helpers.ListPlugins(srv.pluginDIR)
+		require.NoError(t, err)
+
+		// the plugin keeps running with an unchanged configuration.
+		srv.loadNewPlugin(binaries[0], types.PluginConfig{})
+		require.Equal(t, legacy, srv.pluginSet["template_plugin"])
+
+		newConf := types.PluginConfig{Key: "new key", DataUpdateInterval: 60}
+		srv.loadNewPlugin(binaries[0], newConf)
+		require.NotEqual(t, legacy, srv.pluginSet["template_plugin"])
+		require.Equal(t, newConf, *srv.pluginSet["template_plugin"].Config())
+
+		restarted := srv.pluginSet["template_plugin"]
+		srv.loadNewPlugin(binaries[0], newConf)
+		require.Equal(t, restarted, srv.pluginSet["template_plugin"])
+		restarted.Close()
+	})
+
+	t.Run("admin API, unload and reload plugin", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		dialerMock := mock.NewMockDialer(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		contractMock.EXPECT().GetRound(nil).Return(currentRound, nil)
+		contractMock.EXPECT().GetSymbols(nil).Return(config.DefaultSymbols, nil)
+		contractMock.EXPECT().GetPrecision(nil).Return(precision, nil)
+		contractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
+		contractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
+		contractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		contractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+
+		srv := NewOracleServer(conf, dialerMock, l1Mock, contractMock)
+		// run the admin calls as the event loop does.
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				select {
+				case call := <-srv.chAdminCall:
+					call()
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		plugins, err := srv.Plugins()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(plugins))
+		require.Equal(t, "template_plugin", plugins[0].Name)
+		require.Equal(t, types.ProtocolV2, plugins[0].Protocol)
+
+		require.NoError(t, srv.UnloadPlugin("template_plugin"))
+		require.ErrorIs(t, srv.UnloadPlugin("template_plugin"), types.ErrPluginNotFound)
+		_, err = srv.Samples("template_plugin")
+		require.ErrorIs(t, err, types.ErrPluginNotFound)
+
+		// the unloaded plugin is skipped by the runtime discovery until it is reloaded.
+		srv.PluginRuntimeDiscovery()
+		require.Equal(t, 0, len(srv.pluginSet))
+		require.NoError(t, srv.ReloadPlugin("template_plugin"))
+		require.Equal(t, 1, len(srv.pluginSet))
+
+		// a failed reload keeps the running plugin serving.
+		running := srv.pluginSet["template_plugin"]
+		srv.verifyPlugins = true
+		require.ErrorIs(t, srv.ReloadPlugin("template_plugin"), types.ErrMissingChecksum)
+		require.Equal(t, running, srv.pluginSet["template_plugin"])
+		srv.verifyPlugins = false
+		require.ErrorIs(t, srv.ReloadPlugin("unknown_plugin"), types.ErrPluginNotFound)
+
+		state, err := srv.RoundState()
+		require.NoError(t, err)
+		require.Equal(t, currentRound.Uint64(), state.Round)
+		require.Equal(t, votePeriod.Uint64(), state.VotePeriod)
+
+		srv.roundData = make(map[uint64]*types.RoundData)
+		for rd := uint64(1); rd <= 3; rd++ {
+			srv.roundData[rd] = &types.RoundData{RoundID: rd, Salt: new(big.Int).SetUint64(rd)}
+		}
+		rounds, err := srv.RecentRoundData(2)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(rounds))
+		require.Equal(t, uint64(3), rounds[0].RoundID)
+		require.Equal(t, uint64(2), rounds[1].RoundID)
+		srv.pluginSet["template_plugin"].Close()
+	})
+
+	t.Run("fail over to the healthiest L1 endpoint and re-subscribe events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		urls := []string{"ws://127.0.0.1:8546", "ws://127.0.0.2:8546", "ws://127.0.0.3:8546"}
+		dialerMock := mock.NewMockDialer(ctrl)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		newL1Mock := mock.NewMockBlockchain(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		newContractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:         hclog.NewNullLogger(),
+			dialer:         dialerMock,
+			client:         l1Mock,
+			oracleContract: contractMock,
+			signer:         signer.NewKeystoreSigner(conf.Key),
+			l1WSUrl:        urls[0],
+			l1Endpoints:    newL1Endpoints(urls, urls[0]),
+			curRound:       currentRound.Uint64(),
+			bindContract: func(client types.Blockchain) (contract.ContractAPI, error) {
+				require.Equal(t, newL1Mock, client)
+				return newContractMock, nil
+			},
+		}
+
+		// the connected node fails the health check.
+		l1Mock.EXPECT().BlockNumber(gomock.Any()).Return(uint64(0), fmt.Errorf("connection reset"))
+		srv.checkHealth()
+		require.True(t, srv.lostSync)
+
+		// the ends of the other subscriptions of the dropped connection are not counted again.
+		srv.handleConnectivityError()
+		srv.handleConnectivityError()
+		require.Equal(t, MaxEndpointScore/2, srv.l1Endpoints.current.score)
+
+		// the second endpoint is unreachable, the third one is connected, and the events are subscribed on it.
+		gomock.InOrder(
+			dialerMock.EXPECT().Dial(urls[1]).Return(nil, fmt.Errorf("connection refused")),
+			dialerMock.EXPECT().Dial(urls[2]).Return(newL1Mock, nil),
+		)
+		newContractMock.EXPECT().GetRound(nil).Return(currentRound, nil)
+		newContractMock.EXPECT().GetSymbols(nil).Return(config.DefaultSymbols, nil)
+		newContractMock.EXPECT().GetPrecision(nil).Return(precision, nil)
+		newContractMock.EXPECT().GetVotePeriod(nil).Return(votePeriod, nil)
+		newContractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
+		newContractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		newContractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		l1Mock.EXPECT().Close()
+		srv.checkHealth()
+		require.False(t, srv.lostSync)
+		require.Equal(t, urls[2], srv.l1WSUrl)
+		require.Equal(t, newL1Mock, srv.client)
+		require.Equal(t, newContractMock, srv.oracleContract)
+
+		// the failed endpoints are ranked behind the healthy one.
+		ranked := srv.l1Endpoints.ranked()
+		require.Equal(t, urls[2], ranked[0].url)
+		require.Equal(t, urls[0], ranked[1].url)
+		require.Equal(t, urls[1], ranked[2].url)
+
+		// a stalled node is unhealthy too.
+		newL1Mock.EXPECT().BlockNumber(gomock.Any()).Times(2).Return(uint64(100), nil)
+		newContractMock.EXPECT().GetRound(nil).Return(currentRound, nil)
+		srv.checkHealth()
+		require.False(t, srv.lostSync)
+		srv.l1Endpoints.lastHeightAt = time.Now().Add(-StallTimeout)
+		srv.checkHealth()
+		require.True(t, srv.lostSync)
+	})
+
+	t.Run("keep the states on a failed synchronization with a new L1 endpoint", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		url := "ws://127.0.0.1:8546"
+		dialerMock := mock.NewMockDialer(ctrl)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		newL1Mock := mock.NewMockBlockchain(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		newContractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			dialer:          dialerMock,
+			client:          l1Mock,
+			oracleContract:  contractMock,
+			signer:          signer.NewKeystoreSigner(conf.Key),
+			l1WSUrl:         url,
+			l1Endpoints:     newL1Endpoints([]string{url}, url),
+			curRound:        5,
+			protocolSymbols: config.DefaultSymbols,
+			pricePrecision:  decimal.NewFromInt(precision.Int64()),
+			votePeriod:      votePeriod.Uint64(),
+			bindContract: func(client types.Blockchain) (contract.ContractAPI, error) {
+				return newContractMock, nil
+			},
+		}
+
+		// the states are read from the new endpoint, while the subscription of the voted event fails.
+		dialerMock.EXPECT().Dial(url).Return(newL1Mock, nil)
+		newContractMock.EXPECT().GetRound(nil).Return(big.NewInt(9), nil)
+		newContractMock.EXPECT().GetSymbols(nil).Return([]string{"NTN-USD"}, nil)
+		newContractMock.EXPECT().GetPrecision(nil).Return(big.NewInt(100), nil)
+		newContractMock.EXPECT().GetVotePeriod(nil).Return(big.NewInt(60), nil)
+		newContractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Return(subRoundEvent, nil)
+		newContractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Return(subSymbolsEvent, nil)
+		newContractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("connection reset"))
+		newL1Mock.EXPECT().Close()
+		require.ErrorIs(t, srv.failover(), types.ErrNoHealthyEndpoint)
+
+		require.Equal(t, l1Mock, srv.client)
+		require.Equal(t, contractMock, srv.oracleContract)
+		require.Equal(t, uint64(5), srv.curRound)
+		require.Equal(t, config.DefaultSymbols, srv.protocolSymbols)
+		require.True(t, srv.pricePrecision.Equal(decimal.NewFromInt(precision.Int64())))
+		require.Equal(t, votePeriod.Uint64(), srv.votePeriod)
+
+		// a failed read of the states does not zero them either.
+		dialerMock.EXPECT().Dial(url).Return(newL1Mock, nil)
+		newContractMock.EXPECT().GetRound(nil).Return(big.NewInt(9), nil)
+		newContractMock.EXPECT().GetSymbols(nil).Return(nil, fmt.Errorf("connection reset"))
+		newL1Mock.EXPECT().Close()
+		require.ErrorIs(t, srv.failover(), types.ErrNoHealthyEndpoint)
+		require.Equal(t, uint64(5), srv.curRound)
+		require.Equal(t, config.DefaultSymbols, srv.protocolSymbols)
+		require.True(t, srv.pricePrecision.Equal(decimal.NewFromInt(precision.Int64())))
+	})
+
+	t.Run("backfill from the last round seen before the connectivity is lost", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		url := "ws://127.0.0.1:8546"
+		dialerMock := mock.NewMockDialer(ctrl)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		newL1Mock := mock.NewMockBlockchain(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		newContractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			dialer:          dialerMock,
+			client:          l1Mock,
+			oracleContract:  contractMock,
+			signer:          signer.NewKeystoreSigner(conf.Key),
+			l1WSUrl:         url,
+			l1Endpoints:     newL1Endpoints([]string{url}, url),
+			curRound:        1,
+			curSampleHeight: 30,
+			votePeriod:      votePeriod.Uint64(),
+			bindContract: func(client types.Blockchain) (contract.ContractAPI, error) {
+				return newContractMock, nil
+			},
+		}
+
+		l1Mock.EXPECT().BlockNumber(gomock.Any()).Return(uint64(0), fmt.Errorf("connection reset"))
+		srv.checkHealth()
+		require.True(t, srv.lostSync)
+
+		// the first attempt fails partway, after the current round of L1 is read.
+		dialerMock.EXPECT().Dial(url).Times(2).Return(newL1Mock, nil)
+		newContractMock.EXPECT().GetRound(nil).Times(2).Return(big.NewInt(3), nil)
+		newContractMock.EXPECT().GetSymbols(nil).Times(2).Return(config.DefaultSymbols, nil)
+		newContractMock.EXPECT().GetPrecision(nil).Times(2).Return(precision, nil)
+		newContractMock.EXPECT().GetVotePeriod(nil).Times(2).Return(votePeriod, nil)
+		newContractMock.EXPECT().WatchNewRound(gomock.Any(), gomock.Any()).Times(2).Return(subRoundEvent, nil)
+		newContractMock.EXPECT().WatchNewSymbols(gomock.Any(), gomock.Any()).Times(2).Return(subSymbolsEvent, nil)
+		gomock.InOrder(
+			newContractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("connection reset")),
+			newContractMock.EXPECT().WatchVoted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil),
+		)
+		newL1Mock.EXPECT().Close()
+		srv.checkHealth()
+		require.True(t, srv.lostSync)
+
+		// the second attempt succeeds, the rounds are backfilled from the last round seen before the loss.
+		l1Mock.EXPECT().Close()
+		newL1Mock.EXPECT().BlockNumber(gomock.Any()).Return(uint64(100), nil)
+		newContractMock.EXPECT().FilterNewRound(gomock.Any()).DoAndReturn(func(opts *bind.FilterOpts) (*contract.OracleNewRoundIterator, error) {
+			require.Equal(t, uint64(31), opts.Start)
+			return nil, fmt.Errorf("no logs")
+		})
+		srv.checkHealth()
+		require.False(t, srv.lostSync)
+		require.Equal(t, uint64(3), srv.curRound)
+	})
+
+	t.Run("backfill missed rounds and reveal the last round after reconnection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// the NewRound events emitted during the disconnection are served by a binding on the mocked logs.
+		oracleABI, err := contract.OracleMetaData.GetAbi()
+		require.NoError(t, err)
+		newRoundLog := func(round, height uint64) tp.Log {
+			data, err := oracleABI.Events["NewRound"].Inputs.Pack(new(big.Int).SetUint64(round),
+				new(big.Int).SetUint64(height), new(big.Int).SetUint64(height*10), votePeriod)
+			require.NoError(t, err)
+			return tp.Log{Address: types.OracleContractAddress, Topics: []common.Hash{oracleABI.Events["NewRound"].ID},
+				Data: data, BlockNumber: height}
+		}
+		logsMock := mock.NewMockBlockchain(ctrl)
+		logsMock.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, q ethereum.FilterQuery) ([]tp.Log, error) {
+				require.Equal(t, uint64(31), q.FromBlock.Uint64())
+				require.Equal(t, uint64(100), q.ToBlock.Uint64())
+				return []tp.Log{newRoundLog(2, 60), newRoundLog(3, 90)}, nil
+			})
+		binding, err := contract.NewOracle(types.OracleContractAddress, logsMock)
+		require.NoError(t, err)
+		it, err := binding.FilterNewRound(&bind.FilterOpts{Start: 31, End: func() *uint64 { h := uint64(100); return &h }()})
+		require.NoError(t, err)
+
+		store, err := roundstore.NewRoundStore(t.TempDir())
+		require.NoError(t, err)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		key := signer.NewKeystoreSigner(conf.Key)
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			client:          l1Mock,
+			oracleContract:  contractMock,
+			signer:          key,
+			feeStrategy:     feestrategy.NewStaticStrategy(1, config.DefaultGasLimitMax),
+			roundData:       make(map[uint64]*types.RoundData),
+			roundStore:      store,
+			pluginSet:       make(map[string]*pWrapper.PluginWrapper),
+			aggregators:     &aggregator.Aggregators{},
+			pricePrecision:  decimal.NewFromInt(precision.Int64()),
+			curRound:        3,
+			votePeriod:      votePeriod.Uint64(),
+			curSampleHeight: 30,
+		}
+		salt := big.NewInt(7)
+		srv.roundData[2] = &types.RoundData{RoundID: 2, Salt: salt, Symbols: []string{"NTN-USD"},
+			Prices: types.PriceBySymbol{"NTN-USD": {Symbol: "NTN-USD", Price: decimal.RequireFromString("1.5")}}}
+
+		l1Mock.EXPECT().BlockNumber(gomock.Any()).Return(uint64(100), nil)
+		contractMock.EXPECT().FilterNewRound(gomock.Any()).DoAndReturn(func(opts *bind.FilterOpts) (*contract.OracleNewRoundIterator, error) {
+			require.Equal(t, uint64(31), opts.Start)
+			require.Equal(t, uint64(100), *opts.End)
+			return it, nil
+		})
+		l1Mock.EXPECT().SyncProgress(gomock.Any()).Return(nil, nil)
+		contractMock.EXPECT().GetSymbols(nil).Return([]string{"NTN-USD"}, nil)
+		contractMock.EXPECT().GetVoters(nil).Return([]common.Address{key.Address()}, nil)
+		l1Mock.EXPECT().ChainID(gomock.Any()).Return(big.NewInt(1000), nil)
+		l1Mock.EXPECT().PendingNonceAt(gomock.Any(), key.Address()).Return(uint64(1), nil)
+		reveal := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Nonce: 1})
+		contractMock.EXPECT().Vote(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(opts *bind.TransactOpts, commit *big.Int, votes []*big.Int, s *big.Int) (*tp.Transaction, error) {
+				// no samples of the missed round are available, only the last round's commitment is revealed.
+				require.Equal(t, 0, commit.Sign())
+				require.Equal(t, salt, s)
+				require.Equal(t, int64(15000000), votes[0].Int64())
+				return reveal, nil
+			})
+
+		srv.backfillRounds(1, 30)
+		require.Equal(t, uint64(3), srv.curRound)
+		require.Equal(t, uint64(90), srv.curSampleHeight)
+		require.Equal(t, uint64(900), srv.curSampleTS)
+		require.Equal(t, reveal.Hash(), srv.pendingVote.latest().Hash())
+
+		// the backfilled round is seen by the new subscription too, it is not voted again.
+		srv.handleNewRoundEvent(&contract.OracleNewRound{Round: big.NewInt(3), Height: big.NewInt(95),
+			Timestamp: big.NewInt(950), VotePeriod: votePeriod})
+		require.Equal(t, uint64(90), srv.curSampleHeight)
+		require.Equal(t, reveal.Hash(), srv.pendingVote.latest().Hash())
+	})
+
+	t.Run("dry-run, compute votes without submitting and report deviations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		aggregators, err := aggregator.NewAggregators(&types.AggregationConfig{})
+		require.NoError(t, err)
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:         hclog.NewNullLogger(),
+			client:         l1Mock,
+			oracleContract: contractMock,
+			signer:         signer.NewKeystoreSigner(conf.Key),
+			roundData:      make(map[uint64]*types.RoundData),
+			pluginSet:      make(map[string]*pWrapper.PluginWrapper),
+			aggregators:    aggregators,
+			pricePrecision: decimal.NewFromInt(precision.Int64()),
+			dryRun:         true,
+			shadowRounds:   make(map[uint64]*types.RoundData),
+			curRound:       10,
+			curSampleTS:    uint64(time.Now().Unix()),
+		}
+		pw := pWrapper.NewPluginWrapper(hclog.NoLevel, "p1", t.TempDir(), nil, &types.PluginConfig{})
+		pw.AddSample([]types.Price{{Symbol: "NTN-USD", Price: decimal.RequireFromString("10.5"),
+			Timestamp: int64(srv.curSampleTS)}}, int64(srv.curSampleTS))
+		srv.pluginSet["p1"] = pw
+
+		// no vote is submitted, neither the voter is checked.
+		l1Mock.EXPECT().SyncProgress(gomock.Any()).Times(3).Return(nil, nil)
+		contractMock.EXPECT().GetSymbols(nil).Times(3).Return([]string{"NTN-USD"}, nil)
+		require.NoError(t, srv.handleRoundVote())
+		require.Equal(t, 1, len(srv.shadowRounds))
+		require.Equal(t, 0, len(srv.roundData))
+		require.Nil(t, srv.pendingVote)
+
+		// the prices committed in round 10 are aggregated in round 11, they are compared once round 11 is over.
+		srv.curRound = 11
+		require.NoError(t, srv.handleRoundVote())
+		require.Equal(t, 0, len(srv.shadowReports))
+		contractMock.EXPECT().GetRoundData(nil, big.NewInt(11), "NTN-USD").Return(contract.IOracleRoundData{
+			Round:     big.NewInt(11),
+			Price:     new(big.Int).Mul(big.NewInt(10), precision),
+			Timestamp: big.NewInt(int64(srv.curSampleTS)),
+			Status:    OnChainRoundSuccess,
+		}, nil)
+		srv.curRound = 12
+		require.NoError(t, srv.handleRoundVote())
+		require.Equal(t, 1, len(srv.shadowReports))
+		report := srv.shadowReports[0]
+		require.Equal(t, uint64(10), report.Round)
+		require.Equal(t, "NTN-USD", report.Symbols[0].Symbol)
+		require.True(t, report.Symbols[0].OnChain.Equal(decimal.RequireFromString("10")))
+		require.InDelta(t, 0.05, report.Symbols[0].Deviation, 1e-9)
+		require.Equal(t, 2, len(srv.shadowRounds))
+	})
+
+	t.Run("score the revealed prices against the on-chain prices by symbols and plugins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:         hclog.NewNullLogger(),
+			oracleContract: contractMock,
+			roundData:      make(map[uint64]*types.RoundData),
+			pricePrecision: decimal.NewFromInt(precision.Int64()),
+		}
+
+		// the prices committed in round 8 are revealed and aggregated in round 9, they are scored on round 10.
+		srv.roundData[8] = &types.RoundData{
+			RoundID: 8,
+			Symbols: []string{"NTN-USD", "EUR-USD"},
+			Prices: types.PriceBySymbol{
+				"NTN-USD": {Symbol: "NTN-USD", Price: decimal.RequireFromString("10.1")},
+				"EUR-USD": {Symbol: "EUR-USD", Price: decimal.RequireFromString("1.08")},
+			},
+			Sources: map[string]types.PriceByPlugin{
+				"NTN-USD": {"p1": decimal.RequireFromString("10"), "p2": decimal.RequireFromString("10.2")},
+				"EUR-USD": {"p1": decimal.RequireFromString("1.08")},
+			},
+		}
+		contractMock.EXPECT().GetRoundData(nil, big.NewInt(9), "NTN-USD").Return(contract.IOracleRoundData{
+			Round: big.NewInt(9), Price: new(big.Int).Mul(big.NewInt(10), precision), Status: OnChainRoundSuccess,
+		}, nil)
+		contractMock.EXPECT().GetRoundData(nil, big.NewInt(9), "EUR-USD").Return(contract.IOracleRoundData{
+			Round: big.NewInt(9), Price: big.NewInt(0), Status: big.NewInt(1),
+		}, nil)
+		srv.scoreRound(10)
+		// a round is scored only once.
+		srv.scoreRound(10)
+
+		report := srv.accuracy.report()
+		require.Equal(t, 2, len(report.Symbols))
+		require.Equal(t, "NTN-USD", report.Symbols[0].Name)
+		require.InDelta(t, 0.01, report.Symbols[0].MeanDeviation, 1e-9)
+		require.Equal(t, "EUR-USD", report.Symbols[1].Name)
+		require.Equal(t, 1, report.Symbols[1].Failed)
+
+		// p2 drags the votes away from the consensus.
+		require.Equal(t, 2, len(report.Plugins))
+		require.Equal(t, "p2", report.Plugins[0].Name)
+		require.InDelta(t, 0.02, report.Plugins[0].MeanDeviation, 1e-9)
+		require.Equal(t, "p1", report.Plugins[1].Name)
+		require.Equal(t, 0.0, report.Plugins[1].MeanDeviation)
+	})
+
+	t.Run("confirm the submitted votes with the voted events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		key := signer.NewKeystoreSigner(conf.Key)
+		oracleABI, err := contract.OracleMetaData.GetAbi()
+		require.NoError(t, err)
+		votedLog := func(height uint64, votes []*big.Int) tp.Log {
+			data, err := oracleABI.Events["Voted"].Inputs.NonIndexed().Pack(votes)
+			require.NoError(t, err)
+			return tp.Log{Address: types.OracleContractAddress, Data: data, BlockNumber: height,
+				Topics: []common.Hash{oracleABI.Events["Voted"].ID, common.BytesToHash(key.Address().Bytes())}}
+		}
+
+		contractMock := cMock.NewMockContractAPI(ctrl)
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			oracleContract:  contractMock,
+			signer:          key,
+			roundData:       make(map[uint64]*types.RoundData),
+			curRound:        5,
+			curSampleHeight: 120,
+		}
+		votes := []*big.Int{big.NewInt(100), big.NewInt(200)}
+
+		// the voted event of the submitted vote confirms it.
+		srv.expectVoted(5, votes)
+		srv.roundData[5] = &types.RoundData{RoundID: 5}
+		srv.handleVotedEvent(&contract.OracleVoted{Voter: key.Address(), Votes: []*big.Int{big.NewInt(100),
+			big.NewInt(200)}, Raw: tp.Log{BlockNumber: 125}})
+		require.True(t, srv.submittedVote.confirmed)
+		require.True(t, srv.roundData[5].Confirmed)
+		srv.curRound, srv.curSampleHeight = 6, 150
+		srv.reconcileVote()
+		require.Nil(t, srv.submittedVote)
+
+		// a mismatched voted event does not confirm the vote, the vote is flagged after the lookup of the round blocks.
+		srv.expectVoted(6, votes)
+		srv.roundData[6] = &types.RoundData{RoundID: 6}
+		srv.handleVotedEvent(&contract.OracleVoted{Voter: key.Address(), Votes: []*big.Int{big.NewInt(100)},
+			Raw: tp.Log{BlockNumber: 155}})
+		require.True(t, srv.submittedVote.mismatched)
+		require.False(t, srv.submittedVote.confirmed)
+
+		logsMock := mock.NewMockBlockchain(ctrl)
+		logsMock.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).Return(
+			[]tp.Log{votedLog(155, []*big.Int{big.NewInt(100)})}, nil)
+		binding, err := contract.NewOracle(types.OracleContractAddress, logsMock)
+		require.NoError(t, err)
+		contractMock.EXPECT().FilterVoted(gomock.Any(), []common.Address{key.Address()}).DoAndReturn(
+			func(opts *bind.FilterOpts, voters []common.Address) (*contract.OracleVotedIterator, error) {
+				require.Equal(t, uint64(150), opts.Start)
+				require.Equal(t, uint64(180), *opts.End)
+				return binding.FilterVoted(opts, voters)
+			})
+		srv.curRound, srv.curSampleHeight = 7, 180
+		srv.reconcileVote()
+		require.Nil(t, srv.submittedVote)
+		require.False(t, srv.roundData[6].Confirmed)
+
+		// the voted event missed during a disconnection is found by the lookup of the round blocks.
+		srv.expectVoted(7, votes)
+		srv.roundData[7] = &types.RoundData{RoundID: 7}
+		logsMock.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).Return([]tp.Log{votedLog(190, votes)}, nil)
+		contractMock.EXPECT().FilterVoted(gomock.Any(), []common.Address{key.Address()}).DoAndReturn(
+			func(opts *bind.FilterOpts, voters []common.Address) (*contract.OracleVotedIterator, error) {
+				return binding.FilterVoted(opts, voters)
+			})
+		srv.curRound, srv.curSampleHeight = 8, 210
+		srv.reconcileVote()
+		require.Nil(t, srv.submittedVote)
+		require.True(t, srv.roundData[7].Confirmed)
+	})
+
+	t.Run("balance monitor, alert the low runway and request a top-up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sink := &chanSink{events: make(chan *notifier.Event, 10)}
+		n := notifier.NewNotifier(hclog.NoLevel, sink)
+		n.Start()
+		defer n.Stop()
+
+		l1Mock := mock.NewMockBlockchain(ctrl)
+		srv := &OracleServer{
+			logger:         hclog.NewNullLogger(),
+			client:         l1Mock,
+			signer:         signer.NewKeystoreSigner(conf.Key),
+			balanceMonitor: newBalanceMonitor(500, 10),
+			notifier:       n,
+			curRound:       100,
+		}
+		// each vote costs at most 100 wei.
+		vote := tp.NewTx(&tp.DynamicFeeTx{ChainID: big.NewInt(1000), Gas: 10, GasFeeCap: big.NewInt(10)})
+
+		// the runway of 50 rounds is healthy.
+		l1Mock.EXPECT().BalanceAt(gomock.Any(), srv.signer.Address(), nil).Return(big.NewInt(5000), nil)
+		srv.checkBalance(vote)
+		require.False(t, srv.balanceMonitor.alerted)
+
+		// the runway of 9 rounds is below the threshold, a top-up to restore twice the threshold is requested.
+		l1Mock.EXPECT().BalanceAt(gomock.Any(), srv.signer.Address(), nil).Return(big.NewInt(900), nil)
+		srv.checkBalance(vote)
+		event := <-sink.events
+		require.Equal(t, notifier.EventBalanceLow, event.Type)
+		require.Equal(t, "9", event.Fields["runway"])
+		require.Equal(t, "1100", event.Fields["topUp"])
+
+		// the alert is not repeated in the next rounds.
+		srv.curRound = 101
+		l1Mock.EXPECT().BalanceAt(gomock.Any(), srv.signer.Address(), nil).Return(big.NewInt(800), nil)
+		srv.checkBalance(vote)
+
+		// the recovery is notified once the account is topped up.
+		l1Mock.EXPECT().BalanceAt(gomock.Any(), srv.signer.Address(), nil).Return(big.NewInt(2000), nil)
+		srv.checkBalance(vote)
+		event = <-sink.events
+		require.Equal(t, notifier.EventBalanceRecovered, event.Type)
+		require.Equal(t, 0, len(sink.events))
+	})
+
+	t.Run("notify the operational events", func(t *testing.T) {
+		sink := &chanSink{events: make(chan *notifier.Event, 10)}
+		n := notifier.NewNotifier(hclog.NoLevel, sink)
+		n.Start()
+		defer n.Stop()
+
+		srv := &OracleServer{
+			logger:          hclog.NewNullLogger(),
+			notifier:        n,
+			l1WSUrl:         "ws://127.0.0.1:8546",
+			l1Endpoints:     newL1Endpoints([]string{"ws://127.0.0.1:8546"}, "ws://127.0.0.1:8546"),
+			symbols:         []string{"NTN-USD"},
+			protocolSymbols: []string{"NTN-USD", "ATN-USD"},
+			signer:          signer.NewKeystoreSigner(conf.Key),
+			curRound:        100,
+		}
+
+		// the lost of L1 connectivity is notified once until it is restored.
+		srv.handleConnectivityError()
+		srv.handleConnectivityError()
+		event := <-sink.events
+		require.Equal(t, notifier.EventL1Lost, event.Type)
+		require.Equal(t, notifier.SeverityCritical, event.Severity)
+		require.Equal(t, "ws://127.0.0.1:8546", event.Fields["ws"])
+
+		// only the new symbols are notified.
+		srv.handleNewSymbolsEvent([]string{"NTN-USD", "ATN-USD"})
+		srv.handleNewSymbolsEvent([]string{"NTN-USD", "ATN-USD"})
+		event = <-sink.events
+		require.Equal(t, notifier.EventSymbolsChanged, event.Type)
+		require.Equal(t, "ATN-USD", event.Fields["added"])
+		require.Equal(t, "NTN-USD,ATN-USD", event.Fields["symbols"])
+
+		// the symbols without price are notified.
+		_, err := srv.buildRoundData(100)
+		require.Equal(t, types.ErrNoAvailablePrice, err)
+		event = <-sink.events
+		require.Equal(t, notifier.EventPriceUnavailable, event.Type)
+		require.Equal(t, "NTN-USD,ATN-USD", event.Fields["symbols"])
+
+		// the outcomes of the votes are notified.
+		srv.notifyVote(100, nil, fmt.Errorf("insufficient funds"))
+		event = <-sink.events
+		require.Equal(t, notifier.EventVoteFailed, event.Type)
+		require.Equal(t, "insufficient funds", event.Fields["error"])
+		srv.notifyVote(100, tp.NewTx(&tp.DynamicFeeTx{Nonce: 7}), nil)
+		event = <-sink.events
+		require.Equal(t, notifier.EventVoteSubmitted, event.Type)
+		require.Equal(t, "7", event.Fields["nonce"])
+		require.Equal(t, 0, len(sink.events))
+	})
+
 	t.Run("gcRounddata", func(t *testing.T) {
+		store, err := roundstore.NewRoundStore(t.TempDir())
+		require.NoError(t, err)
 		os := &OracleServer{
-			roundData: make(map[uint64]*types.RoundData),
-			curRound:  100,
+			roundData:  make(map[uint64]*types.RoundData),
+			roundStore: store,
+			curRound:   100,
 		}
 
 		for rd := uint64(1); rd <= 100; rd++ {
 			os.roundData[rd] = &types.RoundData{
 				RoundID: rd,
+				Salt:    new(big.Int).SetUint64(rd),
 			}
+			require.NoError(t, store.Save(os.roundData[rd]))
 		}
 
 		os.gcRoundData()
 		require.Equal(t, types.MaxBufferedRounds, len(os.roundData))
+		persisted, err := store.Load()
+		require.NoError(t, err)
+		require.Equal(t, types.MaxBufferedRounds, len(persisted))
 
 	})
 }
@@ -358,3 +1409,17 @@ func replacePlugins(pluginDir string) error {
 
 	return nil
 }
+
+// chanSink collects the events delivered by the notifier.
+type chanSink struct {
+	events chan *notifier.Event
+}
+
+func (s *chanSink) Name() string {
+	return "chan"
+}
+
+func (s *chanSink) Send(_ context.Context, event *notifier.Event) error {
+	s.events <- event
+	return nil
+}
@@ -0,0 +1,81 @@
+package oracleserver
+
+import (
+	contract "autonity-oracle/contract_binder/contract"
+	"autonity-oracle/types"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"time"
+)
+
+// BackfillTimeout is the max time to query the NewRound events missed during a disconnection.
+var BackfillTimeout = 10 * time.Second
+
+// backfillRounds catches up the round rotations missed during a disconnection from the NewRound events emitted since
+// the last seen round. If the vote period of the latest round is not over yet, it votes at once, thus the commitment of
+// the last round is still revealed from the stored round data.
+func (os *OracleServer) backfillRounds(lastRound, lastHeight uint64) {
+	if os.curRound <= lastRound {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), BackfillTimeout)
+	defer cancel()
+	head, err := os.client.BlockNumber(ctx)
+	if err != nil {
+		os.logger.Error("backfill rounds get block number", "error", err.Error())
+		return
+	}
+
+	// without a round seen before, the latest round rotation happened in a vote period.
+	start := lastHeight + 1
+	if lastHeight == 0 && head > os.votePeriod {
+		start = head - os.votePeriod
+	}
+
+	it, err := os.oracleContract.FilterNewRound(&bind.FilterOpts{Start: start, End: &head, Context: ctx})
+	if err != nil {
+		os.logger.Error("backfill rounds filter new round events", "error", err.Error())
+		return
+	}
+	defer it.Close()
+
+	var latest *contract.OracleNewRound
+	for it.Next() {
+		if latest == nil || it.Event.Round.Cmp(latest.Round) > 0 {
+			latest = it.Event
+		}
+	}
+	if err = it.Error(); err != nil {
+		os.logger.Error("backfill rounds iterate new round events", "error", err.Error())
+		return
+	}
+	if latest == nil {
+		os.logger.Warn("no missed round events found", "from", start, "to", head)
+		return
+	}
+
+	os.logger.Info("backfilled missed rounds", "last seen round", lastRound, "current round", latest.Round.Uint64(),
+		"height", latest.Height.Uint64(), "from", start, "to", head)
+	os.applyNewRound(latest)
+
+	if head >= os.curSampleHeight+os.votePeriod {
+		os.logger.Warn("vote period of the backfilled round is over", "round", os.curRound, "height", head)
+		return
+	}
+
+	err = os.handleRoundVote()
+	if errors.Is(err, types.ErrNoAvailablePrice) && !os.dryRun {
+		// the samples of the missed round are not available, reveal the last round's commitment at least.
+		if lastRoundData, ok := os.roundData[os.curRound-1]; ok {
+			err = os.reportWithoutCommitment(lastRoundData)
+		}
+	}
+	if err != nil {
+		os.logger.Error("vote for the backfilled round", "round", os.curRound, "error", err.Error())
+		return
+	}
+	os.gcDataSamples()
+	os.symbols = os.protocolSymbols
+}
@@ -0,0 +1,84 @@
+package oracleserver
+
+import (
+	metricsserver "autonity-oracle/metrics_server"
+	"autonity-oracle/types"
+	"errors"
+)
+
+// shadowVote builds the round data and the commitment as usual in the dry-run mode, but instead of submitting the vote,
+// it records what would have been voted, and compares the recorded rounds with the on-chain prices once available.
+func (os *OracleServer) shadowVote(round uint64) error {
+	os.compareShadowRounds()
+
+	roundData, err := os.buildRoundData(round)
+	if err != nil {
+		os.logger.Error("dry-run build round data", "error", err.Error())
+		return err
+	}
+	os.shadowRounds[round] = roundData
+	os.logger.Info("dry-run, vote is not submitted", "round", round, "prices", roundData.Prices,
+		"commitment hash", roundData.CommitmentHash.String())
+	return nil
+}
+
+// compareShadowRounds produces the deviation reports of the recorded rounds whose on-chain prices are available, the
+// rounds without on-chain prices are dropped once they are out of the buffered rounds.
+func (os *OracleServer) compareShadowRounds() {
+	for round, roundData := range os.shadowRounds {
+		if aggregatedRound(round) >= os.curRound {
+			continue
+		}
+
+		report, ok := os.deviationReport(roundData)
+		if !ok {
+			if round+types.MaxBufferedRounds <= os.curRound {
+				os.logger.Warn("dry-run, no on-chain prices to compare with", "round", round)
+				delete(os.shadowRounds, round)
+			}
+			continue
+		}
+		delete(os.shadowRounds, round)
+
+		for _, d := range report.Symbols {
+			metricsserver.ShadowDeviationGauge(d.Symbol).Update(d.Deviation)
+			os.logger.Info("dry-run deviation report", "round", round, "symbol", d.Symbol, "shadow", d.Shadow.String(),
+				"on-chain", d.OnChain.String(), "deviation", d.Deviation)
+		}
+		os.shadowReports = append(os.shadowReports, report)
+		if len(os.shadowReports) > types.MaxBufferedRounds {
+			os.shadowReports = os.shadowReports[len(os.shadowReports)-types.MaxBufferedRounds:]
+		}
+	}
+}
+
+// deviationReport compares the recorded prices of a round with the on-chain prices aggregated from the votes of that
+// round, it returns false if the on-chain prices are not available yet.
+func (os *OracleServer) deviationReport(roundData *types.RoundData) (types.DeviationReport, bool) {
+	report := types.DeviationReport{Round: roundData.RoundID}
+	round := aggregatedRound(roundData.RoundID)
+	for _, s := range roundData.Symbols {
+		shadow, ok := roundData.Prices[s]
+		if !ok {
+			continue
+		}
+
+		onChain, err := os.onChainPrice(round, s)
+		if errors.Is(err, errAggregationFailed) {
+			os.logger.Debug("dry-run, no valid on-chain price", "round", round, "symbol", s)
+			continue
+		}
+		if err != nil {
+			os.logger.Debug("dry-run get on-chain price", "round", round, "symbol", s, "error", err.Error())
+			return report, false
+		}
+
+		report.Symbols = append(report.Symbols, types.SymbolDeviation{
+			Symbol:    s,
+			Shadow:    shadow.Price,
+			OnChain:   onChain,
+			Deviation: deviation(shadow.Price, onChain),
+		})
+	}
+	return report, true
+}
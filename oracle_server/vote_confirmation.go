@@ -0,0 +1,102 @@
+package oracleserver
+
+import (
+	contract "autonity-oracle/contract_binder/contract"
+	metricsserver "autonity-oracle/metrics_server"
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+	"math/big"
+	"time"
+)
+
+// ReconcileTimeout is the max time to look up the voted events missed during a disconnection.
+var ReconcileTimeout = 10 * time.Second
+
+// submittedVote is a vote sent in a round, it is waiting for the voted event of the oracle contract to confirm that the
+// votes landed, a receipt only proves that the transaction was mined.
+type submittedVote struct {
+	round       uint64
+	startHeight uint64 // the height on which the round of the vote starts.
+	votes       []*big.Int
+	confirmed   bool
+	mismatched  bool
+}
+
+// expectVoted starts to wait for the voted event of a vote, the vote of the last round is reconciled before the
+// rotation, thus it is only replaced here when the vote of the round is sent again.
+func (os *OracleServer) expectVoted(round uint64, votes []*big.Int) {
+	os.submittedVote = &submittedVote{round: round, startHeight: os.curSampleHeight, votes: votes}
+}
+
+// handleVotedEvent reconciles a voted event of the oracle server account with the submitted vote.
+func (os *OracleServer) handleVotedEvent(voted *contract.OracleVoted) {
+	vote := os.submittedVote
+	if vote == nil || vote.confirmed || voted.Raw.BlockNumber < vote.startHeight {
+		os.logger.Debug("voted event without a submitted vote", "block", voted.Raw.BlockNumber, "TX hash", voted.Raw.TxHash)
+		return
+	}
+
+	if !equalVotes(vote.votes, voted.Votes) {
+		vote.mismatched = true
+		os.logger.Error("voted event mismatches the submitted vote", "round", vote.round, "TX hash", voted.Raw.TxHash,
+			"submitted", vote.votes, "voted", voted.Votes)
+		metrics.GetOrRegisterCounter(metricsserver.VoteMismatchedCounter, nil).Inc(1)
+		return
+	}
+
+	vote.confirmed = true
+	if rd, ok := os.roundData[vote.round]; ok {
+		rd.Confirmed = true
+	}
+	os.logger.Info("vote confirmed by voted event", "round", vote.round, "block", voted.Raw.BlockNumber,
+		"TX hash", voted.Raw.TxHash)
+	metrics.GetOrRegisterCounter(metricsserver.VoteConfirmedCounter, nil).Inc(1)
+}
+
+// reconcileVote flags the vote of the last round if no voted event confirmed it before the round rotation. The voted
+// events emitted during a disconnection are looked up from the blocks of that round before the vote is flagged.
+func (os *OracleServer) reconcileVote() {
+	vote := os.submittedVote
+	if vote == nil || vote.round >= os.curRound {
+		return
+	}
+	os.submittedVote = nil
+
+	if !vote.confirmed && os.curSampleHeight > vote.startHeight {
+		ctx, cancel := context.WithTimeout(context.Background(), ReconcileTimeout)
+		defer cancel()
+		end := os.curSampleHeight
+		it, err := os.oracleContract.FilterVoted(&bind.FilterOpts{Start: vote.startHeight, End: &end, Context: ctx},
+			[]common.Address{os.signer.Address()})
+		if err != nil {
+			os.logger.Error("filter voted events", "error", err.Error())
+		} else {
+			os.submittedVote = vote
+			for it.Next() && !vote.confirmed {
+				os.handleVotedEvent(it.Event)
+			}
+			os.submittedVote = nil
+			it.Close()
+		}
+	}
+
+	if !vote.confirmed {
+		os.logger.Warn("no voted event confirmed the vote before the next round", "round", vote.round,
+			"mismatched", vote.mismatched)
+		metrics.GetOrRegisterCounter(metricsserver.VoteUnconfirmedCounter, nil).Inc(1)
+	}
+}
+
+func equalVotes(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == nil || b[i] == nil || a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
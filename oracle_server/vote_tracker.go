@@ -0,0 +1,222 @@
+package oracleserver
+
+import (
+	metricsserver "autonity-oracle/metrics_server"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"math/big"
+)
+
+var (
+	VoteStuckBlocks      = uint64(5) // a vote without receipt after 5 blocks is considered as stuck.
+	MaxVoteResubmissions = 3         // the maximum number of replacements of a stuck vote in a round.
+	GasBumpPercent       = int64(25) // the percentage to bump the tip and fee cap of a replacement, 10% is the minimum for the tx pool.
+)
+
+// pendingVote is a vote transaction of a round which is waiting for its receipt, it keeps the arguments of the vote,
+// thus the vote can be replaced with the same nonce once it is stuck in the tx pool.
+type pendingVote struct {
+	round         uint64
+	txs           []*tp.Transaction // the transactions sent with the nonce of the vote, the last one is the latest.
+	commit        *big.Int
+	votes         []*big.Int
+	salt          *big.Int
+	sentHeight    uint64 // the height on which the tracking of the current tx starts.
+	resubmissions int
+}
+
+// trackVote starts to track the vote of a new round, the previous vote is finalized before it is replaced by the new one.
+func (os *OracleServer) trackVote(vote *pendingVote) {
+	if os.pendingVote != nil {
+		os.checkPendingVote()
+	}
+	os.pendingVote = vote
+}
+
+// latest returns the latest transaction sent for the vote.
+func (v *pendingVote) latest() *tp.Transaction {
+	return v.txs[len(v.txs)-1]
+}
+
+// voteReceipt returns the receipt of any of the transactions sent for the vote, since any of them can be the mined one
+// after a replacement, ethereum.NotFound is returned if none of them is mined.
+func (os *OracleServer) voteReceipt(vote *pendingVote) (*tp.Receipt, error) {
+	for i := len(vote.txs) - 1; i >= 0; i-- {
+		receipt, err := os.client.TransactionReceipt(context.Background(), vote.txs[i].Hash())
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+// checkPendingVote checks the receipt of the pending vote, the final outcome is logged and exported once the vote is
+// mined or once the round of the vote is over, the vote is replaced with higher gas prices if it is stuck.
+func (os *OracleServer) checkPendingVote() {
+	vote := os.pendingVote
+	if vote == nil {
+		return
+	}
+
+	tx := vote.latest()
+	receipt, err := os.voteReceipt(vote)
+	if err == nil {
+		os.finalizeVote(vote, receipt)
+		return
+	}
+
+	if !errors.Is(err, ethereum.NotFound) {
+		os.logger.Error("get vote receipt", "error", err.Error(), "TX hash", tx.Hash())
+		return
+	}
+
+	// the vote is not mined, and the round of it is over, no more chance to get it mined for that round.
+	if vote.round != os.curRound {
+		os.expireVote(vote)
+		return
+	}
+
+	height, err := os.client.BlockNumber(context.Background())
+	if err != nil {
+		os.logger.Error("check pending vote get block number", "error", err.Error())
+		return
+	}
+
+	if vote.sentHeight == 0 {
+		vote.sentHeight = height
+		return
+	}
+
+	if height-vote.sentHeight < VoteStuckBlocks || vote.resubmissions >= MaxVoteResubmissions {
+		return
+	}
+
+	// it is useless to replace the vote on the round boundary, it will be expired by the next round.
+	if height >= os.curSampleHeight+os.votePeriod {
+		return
+	}
+
+	os.resubmitVote(vote, height)
+}
+
+// expireVote ends the tracking of a vote which is not mined in its round, the nonce of the vote is cancelled unless it
+// is consumed already, thus it does not hold the votes of the next rounds in the tx pool.
+func (os *OracleServer) expireVote(vote *pendingVote) {
+	tx := vote.latest()
+	consumed, err := os.nonceConsumed(tx.Nonce())
+	if err != nil {
+		os.logger.Error("check nonce of expired vote", "round", vote.round, "Nonce", tx.Nonce(), "error", err.Error())
+		return
+	}
+
+	// one of the vote transactions can be mined after its receipt was queried.
+	if consumed {
+		if receipt, err := os.voteReceipt(vote); err == nil {
+			os.finalizeVote(vote, receipt)
+			return
+		}
+	}
+
+	os.logger.Warn("vote outcome", "round", vote.round, "status", "expired", "TX hash", tx.Hash(),
+		"Nonce", tx.Nonce(), "resubmissions", vote.resubmissions)
+	metrics.GetOrRegisterCounter(metricsserver.VoteExpiredCounter, nil).Inc(1)
+	os.pendingVote = nil
+
+	if consumed {
+		os.logger.Info("nonce of expired vote consumed by another transaction", "round", vote.round,
+			"Nonce", tx.Nonce())
+		return
+	}
+
+	cancelTx, err := os.cancelNonce(tx)
+	if err != nil {
+		os.logger.Error("cancel nonce of expired vote", "round", vote.round, "Nonce", tx.Nonce(), "error", err.Error())
+		return
+	}
+	os.logger.Warn("cancelled nonce of expired vote", "round", vote.round, "Nonce", cancelTx.Nonce(),
+		"TX hash", cancelTx.Hash())
+	metrics.GetOrRegisterCounter(metricsserver.VoteCancelledCounter, nil).Inc(1)
+}
+
+func (os *OracleServer) finalizeVote(vote *pendingVote, receipt *tp.Receipt) {
+	if receipt.Status == tp.ReceiptStatusSuccessful {
+		os.logger.Info("vote outcome", "round", vote.round, "status", "mined", "TX hash", receipt.TxHash,
+			"block", receipt.BlockNumber, "gas used", receipt.GasUsed, "resubmissions", vote.resubmissions)
+		metrics.GetOrRegisterCounter(metricsserver.VoteMinedCounter, nil).Inc(1)
+	} else {
+		os.logger.Error("vote outcome", "round", vote.round, "status", "reverted", "TX hash", receipt.TxHash,
+			"block", receipt.BlockNumber, "gas used", receipt.GasUsed, "resubmissions", vote.resubmissions)
+		metrics.GetOrRegisterCounter(metricsserver.VoteRevertedCounter, nil).Inc(1)
+	}
+	os.pendingVote = nil
+}
+
+// resubmitVote replaces the stuck vote by a transaction with the same nonce and arguments but with bumped gas prices.
+func (os *OracleServer) resubmitVote(vote *pendingVote, height uint64) {
+	stuck := vote.latest()
+	auth, err := os.newTransactor()
+	if err != nil {
+		return
+	}
+
+	// the replacement takes the higher of the bumped prices and the prices picked with the raised urgency.
+	if err = os.setVoteFees(auth, vote.commit, vote.votes, vote.salt); err != nil {
+		return
+	}
+	auth.Nonce = new(big.Int).SetUint64(stuck.Nonce())
+	auth.GasLimit = stuck.Gas()
+	auth.GasTipCap = maxGasPrice(bumpGasPrice(stuck.GasTipCap()), auth.GasTipCap)
+	auth.GasFeeCap = maxGasPrice(bumpGasPrice(stuck.GasFeeCap()), auth.GasFeeCap)
+	if auth.GasFeeCap.Cmp(auth.GasTipCap) < 0 {
+		auth.GasFeeCap = new(big.Int).Set(auth.GasTipCap)
+	}
+
+	tx, err := os.oracleContract.Vote(auth, vote.commit, vote.votes, vote.salt)
+	if err != nil {
+		os.logger.Error("replace stuck vote", "round", vote.round, "error", err.Error(), "TX hash", stuck.Hash())
+		return
+	}
+
+	os.logger.Warn("replaced stuck vote", "round", vote.round, "old TX hash", stuck.Hash(), "new TX hash", tx.Hash(),
+		"Nonce", tx.Nonce(), "tip", tx.GasTipCap(), "fee cap", tx.GasFeeCap())
+	metrics.GetOrRegisterCounter(metricsserver.VoteResubmittedCounter, nil).Inc(1)
+
+	// the replaced transactions are kept, thus the vote is tracked even if one of them is mined before the replacement.
+	vote.txs = append(vote.txs, tx)
+	vote.sentHeight = height
+	vote.resubmissions++
+
+	// keep the round data in line with the transaction on the fly.
+	if rd, ok := os.roundData[vote.round]; ok {
+		rd.Tx = tx
+		if err = os.roundStore.Save(rd); err != nil {
+			os.logger.Error("cannot persist round data", "round", vote.round, "error", err.Error())
+		}
+	}
+}
+
+// bumpGasPrice returns the price increased by GasBumpPercent, and it is increased at least by 1 wei.
+func bumpGasPrice(price *big.Int) *big.Int {
+	if price == nil {
+		return big.NewInt(1)
+	}
+	bumped := new(big.Int).Mul(price, big.NewInt(100+GasBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(price) <= 0 {
+		bumped.Add(price, big.NewInt(1))
+	}
+	return bumped
+}
+
+func maxGasPrice(a, b *big.Int) *big.Int {
+	if b == nil || a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
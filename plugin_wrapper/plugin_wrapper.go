@@ -1,7 +1,11 @@
 package pluginwrapper
 
 import (
+	metricsserver "autonity-oracle/metrics_server"
 	"autonity-oracle/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/event"
@@ -9,6 +13,7 @@ import (
 	"github.com/hashicorp/go-plugin"
 	"os"
 	"os/exec"
+	"sort"
 	"sync"
 	"time"
 )
@@ -17,6 +22,8 @@ import (
 // plugin, buffers recent data samples measured from the corresponding plugin.
 type PluginWrapper struct {
 	version     string
+	protocol    int               // the negotiated version of the plugin protocol.
+	metadata    types.PluginState // the state reported by the plugin on the initialization.
 	conf        *types.PluginConfig
 	lockService sync.RWMutex
 	lockSamples sync.RWMutex
@@ -26,6 +33,7 @@ type PluginWrapper struct {
 	name        string
 	startAt     time.Time
 	logger      hclog.Logger
+	confPipe    *os.File // the read end of the pipe carrying the configuration, it is closed once the plugin is started.
 
 	doneCh         chan struct{}
 	chSampleEvent  chan *types.SampleEvent
@@ -41,17 +49,27 @@ func NewPluginWrapper(logLevel hclog.Level, name string, pluginDir string, sub t
 		Level:  logLevel,
 	})
 
-	// pluginMap is the map of plugins we can dispense.
-	var pluginMap = map[string]plugin.Plugin{
-		"adapter": &types.AdapterPlugin{},
+	// the plugin configuration is delivered through a pipe passed to the plugin process as an extra file, thus the API
+	// keys are neither visible in the env of the oracle server nor in the env of any plugin process.
+	cmd := exec.Command(fmt.Sprintf("%s/%s", pluginDir, name)) //nolint
+	pipe, err := confPipe(conf)
+	if err != nil {
+		// the plugin is refused to start by Initialize without the pipe.
+		logger.Error("cannot deliver plugin's configuration", "error", err.Error())
+	} else {
+		cmd.ExtraFiles = []*os.File{pipe}
+		cmd.Env = []string{fmt.Sprintf("%s=%d", types.EnvPluginConfFD, 3)}
 	}
 
-	// We're a host! Create the plugin life cycle object with configuration
+	// We're a host! Create the plugin life cycle object with configuration, the protocol version and the transport of
+	// it are negotiated with the plugin. The binary is verified with its checksum before it is started if it is set.
 	pg := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: types.HandshakeConfig,
-		Plugins:         pluginMap,
-		Cmd:             exec.Command(fmt.Sprintf("%s/%s", pluginDir, name)), //nolint
-		Logger:          logger,
+		HandshakeConfig:  types.HandshakeConfig,
+		VersionedPlugins: types.VersionedPlugins,
+		AllowedProtocols: types.AllowedProtocols,
+		Cmd:              cmd,
+		SecureConfig:     secureConfig(conf),
+		Logger:           logger,
 	})
 
 	p := &PluginWrapper{
@@ -64,11 +82,64 @@ func NewPluginWrapper(logLevel hclog.Level, name string, pluginDir string, sub t
 		samples:       make(map[string]map[int64]types.Price),
 		chSampleEvent: make(chan *types.SampleEvent),
 		logger:        logger,
+		confPipe:      pipe,
 	}
 
 	return p
 }
 
+// confPipe returns the read end of a pipe carrying the plugin configuration in JSON, the first extra file of a process
+// is the file descriptor 3 of it.
+func confPipe(conf *types.PluginConfig) (*os.File, error) {
+	c, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		// the write fails rather than blocks once the read end is closed by both processes without reading it.
+		_, _ = w.Write(c)
+		w.Close()
+	}()
+	return r, nil
+}
+
+// VerifyChecksum checks the plugin binary with the SHA-256 checksum set in the plugin config, a binary without a checksum
+// set is not checked.
+func VerifyChecksum(pluginDir string, name string, conf *types.PluginConfig) error {
+	sc := secureConfig(conf)
+	if sc == nil {
+		return nil
+	}
+
+	ok, err := sc.Check(fmt.Sprintf("%s/%s", pluginDir, name))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return plugin.ErrChecksumsDoNotMatch
+	}
+	return nil
+}
+
+// secureConfig returns the config for go-plugin to verify the plugin binary, an invalid checksum is kept empty thus the
+// verification fails rather than being skipped.
+func secureConfig(conf *types.PluginConfig) *plugin.SecureConfig {
+	if conf == nil || conf.Checksum == "" {
+		return nil
+	}
+
+	checksum, _ := hex.DecodeString(conf.Checksum)
+	return &plugin.SecureConfig{
+		Checksum: checksum,
+		Hash:     sha256.New(),
+	}
+}
+
 func (pw *PluginWrapper) AddSample(prices []types.Price, ts int64) {
 	pw.lockSamples.Lock()
 	defer pw.lockSamples.Unlock()
@@ -112,6 +183,13 @@ func (pw *PluginWrapper) GetSample(symbol string, target int64) (types.Price, er
 		}
 	}
 
+	// reject the nearest sample if it is out of the max age window, thus the symbol is missing rather than stale.
+	if pw.conf != nil && pw.conf.MaxSampleAge > 0 && minDistance > int64(pw.conf.MaxSampleAge) {
+		pw.logger.Warn("rejected stale sample", "symbol", symbol, "target", target, "sampled at", nearestKey,
+			"age", minDistance, "max age", pw.conf.MaxSampleAge)
+		return types.Price{}, types.ErrStaleSample
+	}
+
 	return tsMap[nearestKey], nil
 }
 
@@ -123,6 +201,29 @@ func (pw *PluginWrapper) GCSamples() {
 	}
 }
 
+// Samples returns a copy of the buffered samples by symbols, the samples of a symbol are ordered by the sampling TS.
+func (pw *PluginWrapper) Samples() map[string][]types.Price {
+	pw.lockSamples.RLock()
+	defer pw.lockSamples.RUnlock()
+	samples := make(map[string][]types.Price, len(pw.samples))
+	for symbol, tsMap := range pw.samples {
+		timestamps := make([]int64, 0, len(tsMap))
+		for ts := range tsMap {
+			timestamps = append(timestamps, ts)
+		}
+		sort.Slice(timestamps, func(i, j int) bool {
+			return timestamps[i] < timestamps[j]
+		})
+
+		prices := make([]types.Price, 0, len(tsMap))
+		for _, ts := range timestamps {
+			prices = append(prices, tsMap[ts])
+		}
+		samples[symbol] = prices
+	}
+	return samples
+}
+
 func (pw *PluginWrapper) Name() string {
 	return pw.name
 }
@@ -135,10 +236,24 @@ func (pw *PluginWrapper) StartTime() time.Time {
 	return pw.startAt
 }
 
+// Config returns the configuration that the plugin is started with.
+func (pw *PluginWrapper) Config() *types.PluginConfig {
+	return pw.conf
+}
+
 // Initialize start the plugin, connect to it and do a handshake via state() interface.
 func (pw *PluginWrapper) Initialize() error {
+	// without the configuration, the plugin would run on its built-in defaults rather than with its key and options.
+	if pw.confPipe == nil {
+		pw.logger.Error("refuse to start plugin without its configuration")
+		return types.ErrConfNotDelivered
+	}
+
 	// start the plugin process and connect to it
 	rpcClient, err := pw.plugin.Client()
+	// the plugin process holds its own copy of the configuration pipe once it is started.
+	pw.confPipe.Close()
+	pw.confPipe = nil
 	if err != nil {
 		pw.logger.Error("cannot start plugin process", err.Error())
 		return err
@@ -160,13 +275,54 @@ func (pw *PluginWrapper) Initialize() error {
 		return err
 	}
 	pw.version = state.Version
+	pw.protocol = pw.plugin.NegotiatedVersion()
+	pw.metadata = state
 	if state.KeyRequired && pw.conf.Key == "" {
 		return types.ErrMissingServiceKey
 	}
 
+	if err = pw.checkMetadata(); err != nil {
+		return err
+	}
+
 	// all good, start to subscribe data sampling event from oracle server, and listen for sampling.
 	go pw.start()
-	pw.logger.Info("plugin is up and running", pw.name, state)
+	pw.logger.Info("plugin is up and running", pw.name, state, "protocol", pw.protocol)
+	return nil
+}
+
+// ProtocolVersion returns the negotiated version of the plugin protocol.
+func (pw *PluginWrapper) ProtocolVersion() int {
+	return pw.protocol
+}
+
+// Metadata returns the state of the plugin with the metadata of its data source since the v2 protocol.
+func (pw *PluginWrapper) Metadata() types.PluginState {
+	return pw.metadata
+}
+
+// checkMetadata checks the plugin configuration with the metadata of the data source declared by a v2 plugin.
+func (pw *PluginWrapper) checkMetadata() error {
+	if pw.protocol < types.ProtocolV2 {
+		return nil
+	}
+
+	meta := pw.metadata.DataSourceMetadata
+	refresh := pw.conf.DataUpdateInterval
+	if meta.MinRefreshInterval > 0 && refresh > 0 && refresh < meta.MinRefreshInterval {
+		pw.logger.Error("refresh interval is lower than the data source allows", "refresh", refresh,
+			"min refresh", meta.MinRefreshInterval)
+		return types.ErrRefreshTooLow
+	}
+
+	if meta.RateLimit > 0 && refresh > 0 && 3600/refresh > meta.RateLimit {
+		pw.logger.Warn("refresh interval might exceed the rate limit budget of the data source", "refresh", refresh,
+			"requests per hour", meta.RateLimit)
+	}
+
+	if meta.Confidence > 100 {
+		pw.logger.Warn("invalid confidence score of the data source", "confidence", meta.Confidence)
+	}
 	return nil
 }
 
@@ -214,8 +370,11 @@ func (pw *PluginWrapper) fetchPrices(symbols []string, ts int64) error {
 	pw.lockService.Lock()
 	defer pw.lockService.Unlock()
 
+	start := time.Now()
 	report, err := pw.adapter.FetchPrices(symbols)
+	metricsserver.PluginLatencyTimer(pw.name).UpdateSince(start)
 	if err != nil {
+		metricsserver.PluginErrorCounter(pw.name).Inc(1)
 		return err
 	}
 
@@ -225,6 +384,7 @@ func (pw *PluginWrapper) fetchPrices(symbols []string, ts int64) error {
 
 	if len(report.Prices) > 0 {
 		pw.AddSample(report.Prices, ts)
+		metricsserver.PluginSampleCounter(pw.name).Inc(int64(len(report.Prices)))
 	}
 	return nil
 }
@@ -2,8 +2,16 @@ package pluginwrapper
 
 import (
 	"autonity-oracle/types"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -70,8 +78,105 @@ func TestPluginWrapper(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, now+35, price.Timestamp)
 
+		// the buffered samples are ordered by the sampling TS.
+		samples := p.Samples()["NTNGBP"]
+		require.Equal(t, 54, len(samples))
+		require.Equal(t, now, samples[0].Timestamp)
+		require.Equal(t, now+59, samples[53].Timestamp)
+
 		// test gc
 		p.GCSamples()
 		require.Equal(t, 0, len(p.samples))
 	})
+	t.Run("test rejecting stale data sample", func(t *testing.T) {
+		p := PluginWrapper{
+			samples: make(map[string]map[int64]types.Price),
+			conf:    &types.PluginConfig{MaxSampleAge: 5},
+			logger:  hclog.NewNullLogger(),
+		}
+
+		now := time.Now().Unix()
+		p.AddSample([]types.Price{{Timestamp: now, Symbol: "NTNGBP", Price: decimal.RequireFromString("1.1")}}, now)
+
+		price, err := p.GetSample("NTNGBP", now+5)
+		require.NoError(t, err)
+		require.Equal(t, now, price.Timestamp)
+
+		_, err = p.GetSample("NTNGBP", now+6)
+		require.ErrorIs(t, err, types.ErrStaleSample)
+
+		_, err = p.GetSample("NTNGBP", now-6)
+		require.ErrorIs(t, err, types.ErrStaleSample)
+
+		// no limit without the max age.
+		p.conf.MaxSampleAge = 0
+		price, err = p.GetSample("NTNGBP", now+600)
+		require.NoError(t, err)
+		require.Equal(t, now, price.Timestamp)
+	})
+	t.Run("test checking data source metadata", func(t *testing.T) {
+		p := PluginWrapper{
+			protocol: types.ProtocolV2,
+			conf:     &types.PluginConfig{DataUpdateInterval: 5},
+			logger:   hclog.NewNullLogger(),
+		}
+		p.metadata.MinRefreshInterval = 10
+		require.ErrorIs(t, p.checkMetadata(), types.ErrRefreshTooLow)
+
+		p.conf.DataUpdateInterval = 10
+		require.NoError(t, p.checkMetadata())
+
+		// a refresh which might exceed the declared rate limit budget is warned.
+		var logs bytes.Buffer
+		p.logger = hclog.New(&hclog.LoggerOptions{Output: &logs})
+		p.metadata.RateLimit = 300
+		require.NoError(t, p.checkMetadata())
+		require.Contains(t, logs.String(), "might exceed the rate limit budget")
+
+		logs.Reset()
+		p.conf.DataUpdateInterval = 12
+		require.NoError(t, p.checkMetadata())
+		require.Empty(t, logs.String())
+
+		// the legacy plugins do not declare the metadata.
+		p.protocol = types.ProtocolV1
+		p.conf.DataUpdateInterval = 5
+		require.NoError(t, p.checkMetadata())
+	})
+	t.Run("test verifying plugin checksum", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin"), []byte("plugin binary"), 0700))
+		sum := sha256.Sum256([]byte("plugin binary"))
+
+		require.NoError(t, VerifyChecksum(dir, "plugin", &types.PluginConfig{}))
+		require.NoError(t, VerifyChecksum(dir, "plugin", &types.PluginConfig{Checksum: hex.EncodeToString(sum[:])}))
+
+		sum[0]++
+		err := VerifyChecksum(dir, "plugin", &types.PluginConfig{Checksum: hex.EncodeToString(sum[:])})
+		require.ErrorIs(t, err, plugin.ErrChecksumsDoNotMatch)
+
+		// an invalid checksum fails the verification rather than skipping it.
+		err = VerifyChecksum(dir, "plugin", &types.PluginConfig{Checksum: "not a checksum"})
+		require.ErrorIs(t, err, plugin.ErrSecureConfigNoChecksum)
+	})
+	t.Run("test delivering plugin configuration through a pipe", func(t *testing.T) {
+		conf := &types.PluginConfig{Name: "plugin", Key: "secret", Options: map[string]string{"price": "bid"}}
+		pipe, err := confPipe(conf)
+		require.NoError(t, err)
+		defer pipe.Close()
+
+		var delivered types.PluginConfig
+		require.NoError(t, json.NewDecoder(pipe).Decode(&delivered))
+		require.Equal(t, *conf, delivered)
+
+		// the configuration is not set in the env of the oracle server.
+		_, ok := os.LookupEnv("plugin")
+		require.False(t, ok)
+	})
+	t.Run("test refusing to start plugin without its configuration", func(t *testing.T) {
+		p := NewPluginWrapper(hclog.NoLevel, "plugin", t.TempDir(), nil, &types.PluginConfig{Name: "plugin"})
+		p.confPipe.Close()
+		p.confPipe = nil
+		require.ErrorIs(t, p.Initialize(), types.ErrConfNotDelivered)
+	})
 }
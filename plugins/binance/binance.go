@@ -15,6 +15,10 @@ const (
 	version = "v0.0.2"
 	apiPath = "api/v3/ticker/price"
 	symbol  = "symbols"
+
+	// Binance.US limits the request weight to 1200 per minute by IP, a price ticker request of multiple symbols is
+	// counted with a weight of up to 4, thus it is 300 requests per minute.
+	rateLimit = 300 * 60
 )
 
 var defaultConfig = types.PluginConfig{
@@ -46,6 +50,14 @@ func (bi *BIClient) KeyRequired() bool {
 	return false
 }
 
+func (bi *BIClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeCrypto,
+		QuoteCurrencies: []string{"USDT", "USDC"},
+		RateLimit:       rateLimit,
+	}
+}
+
 func (bi *BIClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	u, err := bi.buildURL(symbols)
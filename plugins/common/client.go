@@ -1,6 +1,7 @@
 package common
 
 import (
+	"autonity-oracle/types"
 	"net/http"
 	"net/url"
 	"time"
@@ -18,6 +19,12 @@ type DataSourceClient interface {
 	Close()
 }
 
+// MetadataReporter is implemented by the data source clients which declare the metadata of their data source, it is
+// reported to the oracle server via the v2 plugin protocol.
+type MetadataReporter interface {
+	Metadata() types.DataSourceMetadata
+}
+
 type connection struct {
 	client *http.Client
 	host   string
@@ -7,9 +7,11 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
 	"github.com/shopspring/decimal"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +27,7 @@ var (
 type Price struct {
 	Symbol string `json:"symbol,omitempty"`
 	Price  string `json:"price,omitempty"`
+	Volume string `json:"volume,omitempty"` // optional, the trading volume behind the price.
 }
 
 type Prices []Price
@@ -94,6 +97,15 @@ func (p *Plugin) FetchPrices(symbols []string) (types.PluginPriceReport, error)
 			Symbol:    availableSymMap[v.Symbol], // set the symbol with the symbol style used in oracle server side.
 			Price:     dec,
 		}
+
+		if v.Volume != "" {
+			volume, err := decimal.NewFromString(v.Volume)
+			if err != nil {
+				p.logger.Error("cannot convert volume string to decimal: ", "volume", v.Volume, "error", err.Error())
+			} else {
+				pr.Volume = volume
+			}
+		}
 		p.cachePrices[v.Symbol] = pr
 		report.Prices = append(report.Prices, pr)
 	}
@@ -130,6 +142,9 @@ func (p *Plugin) State() (types.PluginState, error) {
 	state.Version = p.version
 	state.AvailableSymbols = symbols
 	state.KeyRequired = p.client.KeyRequired()
+	if r, ok := p.client.(MetadataReporter); ok {
+		state.DataSourceMetadata = r.Metadata()
+	}
 	return state, nil
 }
 
@@ -140,7 +155,8 @@ func (p *Plugin) Close() {
 }
 
 // resolveSymbols resolve supported symbols of provider, and it builds the mapping of symbols from `-` separated pattern to those
-// pattens supported by data providers, and filter outs those un-supported symbols.
+// pattens supported by data providers, and filter outs those un-supported symbols. The symbol mappings of the plugin
+// configuration override the conversion by the separator.
 func (p *Plugin) resolveSymbols(askedSymbols []string) ([]string, []string, map[string]string) {
 	var supported []string
 	var unRecognizable []string
@@ -148,7 +164,10 @@ func (p *Plugin) resolveSymbols(askedSymbols []string) ([]string, []string, map[
 	symbolsMapping := make(map[string]string)
 
 	for _, askedSym := range askedSymbols {
-		converted := ConvertSymbol(askedSym, p.symbolSeparator)
+		converted, ok := p.conf.Symbols[askedSym]
+		if !ok {
+			converted = ConvertSymbol(askedSym, p.symbolSeparator)
+		}
 		if _, ok := p.availableSymbols[converted]; !ok {
 			unRecognizable = append(unRecognizable, askedSym)
 			continue
@@ -177,12 +196,25 @@ func (p *Plugin) fetchPricesFromCache(availableSymbols []string) ([]types.Price,
 	return prices, nil
 }
 
-// LoadPluginConf is called from plugin main() to load plugin's conf from system env.
+// LoadPluginConf is called from plugin main() to load plugin's conf. The oracle server passes the conf through a pipe set
+// in types.EnvPluginConfFD, thus it does not show in the env of the plugin process, while a plugin started manually, e.g.
+// for debugging, loads it from the system env named after the plugin.
 func LoadPluginConf(cmd string) (*types.PluginConfig, error) {
-	name := filepath.Base(cmd)
-	conf := os.Getenv(name)
+	conf := []byte(os.Getenv(filepath.Base(cmd)))
+	if fd := os.Getenv(types.EnvPluginConfFD); fd != "" {
+		n, err := strconv.Atoi(fd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid conf file descriptor: %s", fd)
+		}
+		f := os.NewFile(uintptr(n), "plugin-conf")
+		defer f.Close()
+		if conf, err = io.ReadAll(f); err != nil {
+			return nil, err
+		}
+	}
+
 	var c types.PluginConfig
-	err := json.Unmarshal([]byte(conf), &c)
+	err := json.Unmarshal(conf, &c)
 	if err != nil {
 		return nil, err
 	}
@@ -236,18 +268,39 @@ func ResolveConf(cmd string, defConf *types.PluginConfig) *types.PluginConfig {
 		conf.Key = defConf.Key
 	}
 
+	// the options and the symbol mappings of the configuration override the default ones of the plugin.
+	conf.Options = mergeOptions(defConf.Options, conf.Options)
+	conf.Symbols = mergeOptions(defConf.Symbols, conf.Symbols)
 	return conf
 }
 
+func mergeOptions(def, set map[string]string) map[string]string {
+	if len(def) == 0 {
+		return set
+	}
+	merged := make(map[string]string, len(def)+len(set))
+	for k, v := range def {
+		merged[k] = v
+	}
+	for k, v := range set {
+		merged[k] = v
+	}
+	return merged
+}
+
 // PluginServe doesn't return until the plugin is done being executed.
 func PluginServe(p *Plugin) {
 	var pluginMap = map[string]plugin.Plugin{
 		"adapter": &types.AdapterPlugin{Impl: p},
 	}
 
+	// serve both versions of the protocol, thus the plugin works with the legacy oracle servers as well.
 	plugin.Serve(&plugin.ServeConfig{
 		HandshakeConfig: types.HandshakeConfig,
-		Plugins:         pluginMap,
+		VersionedPlugins: map[int]plugin.PluginSet{
+			types.ProtocolV1: pluginMap,
+			types.ProtocolV2: pluginMap,
+		},
 	})
 }
 
@@ -1,7 +1,11 @@
 package common
 
 import (
+	"autonity-oracle/types"
+	"encoding/json"
 	"github.com/stretchr/testify/require"
+	"os"
+	"strconv"
 	"testing"
 )
 
@@ -32,3 +36,57 @@ func TestResolveSeparator(t *testing.T) {
 	symbol = "BTCUSD"
 	require.Equal(t, "", ResolveSeparator(symbol))
 }
+
+func TestResolveConf(t *testing.T) {
+	conf := types.PluginConfig{
+		Name:    "plugin",
+		Options: map[string]string{"price": "bid"},
+		Symbols: map[string]string{"NTN-USD": "NTN-USDC"},
+	}
+	content, err := json.Marshal(conf)
+	require.NoError(t, err)
+	t.Setenv("plugin", string(content))
+
+	defConf := types.PluginConfig{
+		Scheme:  "https",
+		Timeout: 10,
+		Options: map[string]string{"price": "mid", "router": "api/orderbooks"},
+	}
+	resolved := ResolveConf("./plugin", &defConf)
+	require.Equal(t, "https", resolved.Scheme)
+	require.Equal(t, map[string]string{"price": "bid", "router": "api/orderbooks"}, resolved.Options)
+	require.Equal(t, conf.Symbols, resolved.Symbols)
+	require.Equal(t, "bid", resolved.Option("price", "mid"))
+	require.Equal(t, "mid", resolved.Option("side", "mid"))
+}
+
+func TestLoadPluginConf(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = w.Write([]byte(`{"name":"plugin","key":"secret"}`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// the conf passed through the pipe takes precedence over the one in the env.
+	t.Setenv("plugin", `{"name":"plugin","key":"from env"}`)
+	t.Setenv(types.EnvPluginConfFD, strconv.Itoa(int(r.Fd())))
+	conf, err := LoadPluginConf("./plugin")
+	require.NoError(t, err)
+	require.Equal(t, "secret", conf.Key)
+
+	t.Setenv(types.EnvPluginConfFD, "stdin")
+	_, err = LoadPluginConf("./plugin")
+	require.Error(t, err)
+}
+
+func TestResolveSymbols(t *testing.T) {
+	p := &Plugin{
+		availableSymbols: map[string]struct{}{"NTN-USDC": {}, "ATN-USD": {}},
+		symbolSeparator:  "-",
+		conf:             &types.PluginConfig{Symbols: map[string]string{"NTN-USD": "NTN-USDC"}},
+	}
+	supported, unRecognizable, mapping := p.resolveSymbols([]string{"NTN-USD", "ATN-USD", "NTN-ATN"})
+	require.Equal(t, []string{"NTN-USDC", "ATN-USD"}, supported)
+	require.Equal(t, []string{"NTN-ATN"}, unRecognizable)
+	require.Equal(t, "NTN-USD", mapping["NTN-USDC"])
+}
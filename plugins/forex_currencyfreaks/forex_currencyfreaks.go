@@ -64,6 +64,13 @@ func (cf *CFClient) KeyRequired() bool {
 	return true
 }
 
+func (cf *CFClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeForex,
+		QuoteCurrencies: []string{"USD"},
+	}
+}
+
 func (cf *CFClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	u := cf.buildURL(cf.conf.Key)
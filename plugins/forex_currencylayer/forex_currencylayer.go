@@ -67,6 +67,13 @@ func (cl *CLClient) KeyRequired() bool {
 	return true
 }
 
+func (cl *CLClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeForex,
+		QuoteCurrencies: []string{"USD"},
+	}
+}
+
 func (cl *CLClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	u := cl.buildURL(cl.conf.Key)
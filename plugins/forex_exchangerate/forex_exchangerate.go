@@ -69,6 +69,13 @@ func (ex *EXClient) KeyRequired() bool {
 	return true
 }
 
+func (ex *EXClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeForex,
+		QuoteCurrencies: []string{"USD"},
+	}
+}
+
 func (ex *EXClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	u := ex.buildURL(ex.conf.Key)
@@ -71,6 +71,13 @@ func (oe *OXClient) KeyRequired() bool {
 	return true
 }
 
+func (oe *OXClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeForex,
+		QuoteCurrencies: []string{"USD"},
+	}
+}
+
 func (oe *OXClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	u := oe.buildURL(oe.conf.Key)
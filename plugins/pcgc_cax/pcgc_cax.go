@@ -4,6 +4,7 @@ import (
 	"autonity-oracle/plugins/common"
 	"autonity-oracle/types"
 	"encoding/json"
+	"fmt"
 	"github.com/hashicorp/go-hclog"
 	"github.com/shopspring/decimal"
 	"io"
@@ -23,6 +24,17 @@ const (
 	ATNUSD  = "ATN-USD"
 )
 
+// The options of the plugin set in the options of its configuration.
+const (
+	optionPrice  = "price"  // the side of the order book to take the price, mid, bid or ask, default is mid.
+	optionRouter = "router" // the path of the order books API, it overrides the default one of the network.
+	optionDerive = "derive" // derive NTN-ATN from NTN-USD and ATN-USD in the plugin, default is true.
+
+	priceMid = "mid"
+	priceBid = "bid"
+	priceAsk = "ask"
+)
+
 // take piccadilly setup as the default setting
 var routers = "api/orderbooks"
 var defaultEndpoint = "cax.piccadilly.autonity.org"
@@ -47,9 +59,21 @@ type CAXClient struct {
 	conf   *types.PluginConfig
 	client *common.Client
 	logger hclog.Logger
+	side   string // the side of the order book to take the price.
+	router string
+	derive bool
 }
 
-func NewCAXClient(conf *types.PluginConfig) *CAXClient {
+func NewCAXClient(conf *types.PluginConfig) (*CAXClient, error) {
+	side := conf.Option(optionPrice, priceMid)
+	if side != priceMid && side != priceBid && side != priceAsk {
+		return nil, fmt.Errorf("unknown price option %s, it should be mid, bid or ask", side)
+	}
+	derive, err := conf.BoolOption(optionDerive, true)
+	if err != nil {
+		return nil, err
+	}
+
 	client := common.NewClient(conf.Key, time.Second*time.Duration(conf.Timeout), conf.Endpoint)
 	logger := hclog.New(&hclog.LoggerOptions{
 		Name:   "AutonityR4CAX",
@@ -61,13 +85,23 @@ func NewCAXClient(conf *types.PluginConfig) *CAXClient {
 		conf:   conf,
 		client: client,
 		logger: logger,
-	}
+		side:   side,
+		router: conf.Option(optionRouter, routers),
+		derive: derive,
+	}, nil
 }
 
 func (cc *CAXClient) KeyRequired() bool {
 	return false
 }
 
+func (cc *CAXClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeCrypto,
+		QuoteCurrencies: []string{"USD"},
+	}
+}
+
 func (cc *CAXClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	priceMap := make(map[string]common.Price)
@@ -91,8 +125,9 @@ func (cc *CAXClient) FetchPrice(symbols []string) (common.Prices, error) {
 		return nil, common.ErrDataNotAvailable
 	}
 
-	// for autonity round4 game, the price of "NTN-ATN" is derived from the price of "NTN-USD" and "ATN-USD"
-	if _, ok := priceMap[NTNATN]; !ok && len(priceMap) == 2 {
+	// for autonity round4 game, the price of "NTN-ATN" is derived from the price of "NTN-USD" and "ATN-USD", unless the
+	// derivation is left to the oracle server.
+	if _, ok := priceMap[NTNATN]; !ok && len(priceMap) == 2 && cc.derive {
 		pNTN, ok := priceMap[NTNUSD]
 		if !ok {
 			cc.logger.Error("missing NTN-USD data to compute derived price: NTN-ATN")
@@ -161,8 +196,15 @@ func (cc *CAXClient) fetchPrice(symbol string) (common.Price, error) {
 		return price, err
 	}
 
-	// the aggregated price takes the average value of ask and bid prices.
-	price.Price = askPrice.Add(bidPrice).Div(decimal.NewFromInt(2)).String()
+	switch cc.side {
+	case priceBid:
+		price.Price = bidPrice.String()
+	case priceAsk:
+		price.Price = askPrice.String()
+	default:
+		// the mid price takes the average value of ask and bid prices.
+		price.Price = askPrice.Add(bidPrice).Div(decimal.NewFromInt(2)).String()
+	}
 	price.Symbol = symbol
 
 	return price, nil
@@ -170,7 +212,7 @@ func (cc *CAXClient) fetchPrice(symbol string) (common.Price, error) {
 
 func (cc *CAXClient) buildURL(symbol string) *url.URL {
 	endpoint := &url.URL{}
-	endpoint.Path = strings.Join([]string{routers, symbol, quote}, "/")
+	endpoint.Path = strings.Join([]string{cc.router, symbol, quote}, "/")
 	return endpoint
 }
 
@@ -202,7 +244,12 @@ func (cc *CAXClient) Close() {
 
 func main() {
 	conf := common.ResolveConf(os.Args[0], &defaultConfig)
-	adapter := common.NewPlugin(conf, NewCAXClient(conf), version)
+	client, err := NewCAXClient(conf)
+	if err != nil {
+		println("invalid plugin configuration: ", err.Error())
+		os.Exit(-1)
+	}
+	adapter := common.NewPlugin(conf, client, version)
 	defer adapter.Close()
 	common.PluginServe(adapter)
 }
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"autonity-oracle/types"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -9,8 +15,49 @@ func TestNewCAXClient(t *testing.T) {
 	// set the CAX of dev-net for testing by default since piccadilly CAX is not ready.
 	defaultConfig.Endpoint = "cax.devnet.clearmatics.network"
 	routers = "orderbooks"
-	client := NewCAXClient(&defaultConfig)
+	client, err := NewCAXClient(&defaultConfig)
+	require.NoError(t, err)
 	prices, err := client.FetchPrice([]string{"ATN-USD", "NTN-USD", "NTN-ATN"})
 	require.NoError(t, err)
 	require.Equal(t, 3, len(prices))
 }
+
+func TestCAXClientOptions(t *testing.T) {
+	// the requested paths are asserted on the test goroutine rather than in the handler.
+	var lock sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		paths = append(paths, r.URL.Path)
+		lock.Unlock()
+		_, _ = w.Write([]byte(`{"timestamp":"1","bid_price":"9","bid_amount":"1","ask_price":"11","ask_amount":"1"}`))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	conf := types.PluginConfig{Scheme: "http", Endpoint: u.Host, Timeout: 5,
+		Options: map[string]string{"price": "bid", "router": "books", "derive": "false"}}
+	client, err := NewCAXClient(&conf)
+	require.NoError(t, err)
+
+	// the bid price is taken, and NTN-ATN is left to the oracle server.
+	prices, err := client.FetchPrice([]string{"ATN-USD", "NTN-USD", "NTN-ATN"})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(prices))
+	require.Equal(t, "9", prices[0].Price)
+
+	lock.Lock()
+	require.Equal(t, 2, len(paths))
+	for _, path := range paths {
+		require.True(t, strings.HasPrefix(path, "/books/"), path)
+	}
+	lock.Unlock()
+
+	conf.Options = map[string]string{"price": "last"}
+	_, err = NewCAXClient(&conf)
+	require.Error(t, err)
+	conf.Options = map[string]string{"derive": "maybe"}
+	_, err = NewCAXClient(&conf)
+	require.Error(t, err)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"autonity-oracle/plugins/common"
+	"autonity-oracle/types"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/shopspring/decimal"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const version = "v0.0.1"
+
+var defaultConfig = types.PluginConfig{
+	Key:                "",
+	Scheme:             "https",
+	Endpoint:           "",
+	Timeout:            10, //10s
+	DataUpdateInterval: 30, //30s
+}
+
+var errZeroPrice = errors.New("cannot invert a zero price")
+
+// RESTClient is a data source client driven by the REST settings of the plugin configuration, thus a new data provider
+// serving the prices in JSON is onboarded by the configuration rather than by a new plugin.
+type RESTClient struct {
+	conf   *types.PluginConfig
+	rest   *types.RESTConfig
+	client *http.Client
+	logger hclog.Logger
+}
+
+func NewRESTClient(conf *types.PluginConfig) (*RESTClient, error) {
+	if err := validateConf(conf); err != nil {
+		return nil, err
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:   conf.Name,
+		Level:  hclog.Info,
+		Output: os.Stdout,
+	})
+	client := &http.Client{Timeout: time.Second * time.Duration(conf.Timeout)}
+	return &RESTClient{conf: conf, rest: conf.REST, client: client, logger: logger}, nil
+}
+
+func validateConf(conf *types.PluginConfig) error {
+	rest := conf.REST
+	if rest == nil {
+		return fmt.Errorf("no rest settings in the configuration of plugin %s", conf.Name)
+	}
+	if conf.Endpoint == "" {
+		return fmt.Errorf("no endpoint set")
+	}
+	if rest.Price == "" {
+		return fmt.Errorf("no price selector set")
+	}
+	if len(conf.Symbols) == 0 {
+		return fmt.Errorf("no symbols set")
+	}
+
+	switch rest.Auth {
+	case "", types.AuthNone:
+	case types.AuthHeader, types.AuthQuery:
+		if rest.AuthName == "" {
+			return fmt.Errorf("no auth_name set for the auth placement %s", rest.Auth)
+		}
+	default:
+		return fmt.Errorf("unknown auth placement %s", rest.Auth)
+	}
+	return nil
+}
+
+func (rc *RESTClient) KeyRequired() bool {
+	return rc.rest.Auth == types.AuthHeader || rc.rest.Auth == types.AuthQuery
+}
+
+func (rc *RESTClient) FetchPrice(symbols []string) (common.Prices, error) {
+	var prices common.Prices
+	if rc.rest.Batch {
+		// a single request serves all the symbols.
+		doc, err := rc.request(rc.vars("", symbols))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range symbols {
+			p, err := rc.parsePrice(doc, s)
+			if err != nil {
+				rc.logger.Error("parse price", "symbol", s, "error", err.Error())
+				continue
+			}
+			prices = append(prices, p)
+		}
+	} else {
+		for _, s := range symbols {
+			doc, err := rc.request(rc.vars(s, []string{s}))
+			if err != nil {
+				rc.logger.Error("request price", "symbol", s, "error", err.Error())
+				continue
+			}
+			p, err := rc.parsePrice(doc, s)
+			if err != nil {
+				rc.logger.Error("parse price", "symbol", s, "error", err.Error())
+				continue
+			}
+			prices = append(prices, p)
+		}
+	}
+
+	if len(prices) == 0 && len(symbols) != 0 {
+		return nil, common.ErrDataNotAvailable
+	}
+	return prices, nil
+}
+
+// AvailableSymbols returns the symbols set in the symbol mapping of the plugin configuration.
+func (rc *RESTClient) AvailableSymbols() ([]string, error) {
+	symbols := make([]string, 0, len(rc.conf.Symbols))
+	for s := range rc.conf.Symbols {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+func (rc *RESTClient) Close() {
+	rc.client.CloseIdleConnections()
+}
+
+func (rc *RESTClient) providerSymbol(symbol string) string {
+	if s := rc.conf.Symbols[symbol]; s != "" {
+		return s
+	}
+	return symbol
+}
+
+// vars returns the replacer of the template variables of a symbol, the symbol specific variables are left empty in a
+// batch request.
+func (rc *RESTClient) vars(symbol string, symbols []string) *strings.Replacer {
+	var providerSymbol, base, quote string
+	if symbol != "" {
+		providerSymbol = rc.providerSymbol(symbol)
+		if codes := strings.Split(symbol, common.ResolveSeparator(symbol)); len(codes) == 2 {
+			base, quote = codes[0], codes[1]
+		}
+	}
+
+	providerSymbols := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		providerSymbols = append(providerSymbols, rc.providerSymbol(s))
+	}
+	return strings.NewReplacer("{symbols}", strings.Join(providerSymbols, ","), "{symbol}", providerSymbol,
+		"{base}", base, "{quote}", quote, "{key}", rc.conf.Key)
+}
+
+func (rc *RESTClient) request(vars *strings.Replacer) (interface{}, error) {
+	endpoint := &url.URL{Scheme: rc.conf.Scheme, Host: rc.conf.Endpoint, Path: vars.Replace(rc.rest.Path)}
+	query := endpoint.Query()
+	for k, v := range rc.rest.Query {
+		query.Set(k, vars.Replace(v))
+	}
+	if rc.rest.Auth == types.AuthQuery {
+		query.Set(rc.rest.AuthName, rc.conf.Key)
+	}
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if rc.rest.Auth == types.AuthHeader {
+		req.Header.Set(rc.rest.AuthName, rc.rest.AuthPrefix+rc.conf.Key)
+	}
+
+	res, err := rc.client.Do(req)
+	if err != nil {
+		// the error carries the URL, thus the key placed in the query has to be redacted before it is logged.
+		if rc.conf.Key != "" {
+			return nil, errors.New(strings.ReplaceAll(err.Error(), url.QueryEscape(rc.conf.Key), "***"))
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err = common.CheckHTTPStatusCode(res.StatusCode); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (rc *RESTClient) parsePrice(doc interface{}, symbol string) (common.Price, error) {
+	var price common.Price
+	vars := rc.vars(symbol, []string{symbol})
+
+	v, err := selectValue(doc, vars.Replace(rc.rest.Price))
+	if err != nil {
+		return price, err
+	}
+	p, err := toDecimal(v)
+	if err != nil {
+		return price, err
+	}
+	if rc.rest.Invert {
+		if p.IsZero() {
+			return price, errZeroPrice
+		}
+		p = decimal.NewFromInt(1).Div(p)
+	}
+	price.Symbol = symbol
+	price.Price = p.String()
+
+	if rc.rest.Volume != "" {
+		v, err = selectValue(doc, vars.Replace(rc.rest.Volume))
+		if err != nil {
+			return price, err
+		}
+		volume, err := toDecimal(v)
+		if err != nil {
+			return price, err
+		}
+		price.Volume = volume.String()
+	}
+	return price, nil
+}
+
+func main() {
+	conf := common.ResolveConf(os.Args[0], &defaultConfig)
+	client, err := NewRESTClient(conf)
+	if err != nil {
+		println("invalid rest configuration: ", err.Error())
+		os.Exit(-1)
+	}
+	adapter := common.NewPlugin(conf, client, version)
+	defer adapter.Close()
+	common.PluginServe(adapter)
+}
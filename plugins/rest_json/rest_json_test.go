@@ -0,0 +1,153 @@
+package main
+
+import (
+	"autonity-oracle/plugins/common"
+	"autonity-oracle/types"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestConf(srv *httptest.Server, rest *types.RESTConfig, symbols map[string]string) *types.PluginConfig {
+	u, _ := url.Parse(srv.URL)
+	return &types.PluginConfig{Name: "rest_json", Key: "secret", Scheme: "http", Endpoint: u.Host, Timeout: 5, REST: rest,
+		Symbols: symbols}
+}
+
+func TestSelectValue(t *testing.T) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{"data":[{"symbol":"EUR.USD","price":"1.08"},{"symbol":"GBP.USD","price":1.27}],"rates":{"JPY":150}}`))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&doc))
+
+	v, err := selectValue(doc, "data[symbol=GBP.USD].price")
+	require.NoError(t, err)
+	p, err := toDecimal(v)
+	require.NoError(t, err)
+	require.Equal(t, "1.27", p.String())
+
+	v, err = selectValue(doc, "data.0.price")
+	require.NoError(t, err)
+	require.Equal(t, "1.08", v)
+
+	v, err = selectValue(doc, "data[1].symbol")
+	require.NoError(t, err)
+	require.Equal(t, "GBP.USD", v)
+
+	v, err = selectValue(doc, "rates.JPY")
+	require.NoError(t, err)
+	require.Equal(t, json.Number("150"), v)
+
+	for _, selector := range []string{"rates.EUR", "data[symbol=SEK.USD].price", "data.2", "rates[0]", "data[.price", "rates..JPY"} {
+		_, err = selectValue(doc, selector)
+		require.Error(t, err, selector)
+	}
+}
+
+func TestRESTClient(t *testing.T) {
+	t.Run("validate the configuration", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		_, err := NewRESTClient(newTestConf(srv, nil, map[string]string{"EUR-USD": ""}))
+		require.Error(t, err)
+		_, err = NewRESTClient(newTestConf(srv, &types.RESTConfig{Price: "price"}, nil))
+		require.Error(t, err)
+		_, err = NewRESTClient(newTestConf(srv, &types.RESTConfig{Price: "price", Auth: types.AuthHeader},
+			map[string]string{"EUR-USD": ""}))
+		require.Error(t, err)
+
+		client, err := NewRESTClient(newTestConf(srv, &types.RESTConfig{Price: "price"},
+			map[string]string{"JPY-USD": "", "EUR-USD": ""}))
+		require.NoError(t, err)
+		require.False(t, client.KeyRequired())
+		symbols, err := client.AvailableSymbols()
+		require.NoError(t, err)
+		require.Equal(t, []string{"EUR-USD", "JPY-USD"}, symbols)
+	})
+
+	t.Run("batch request with the key in the query and the inverted rates", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/v2.0/rates/latest", r.URL.Path)
+			require.Equal(t, "secret", r.URL.Query().Get("apikey"))
+			require.Equal(t, "EUR,JPY", r.URL.Query().Get("symbols"))
+			_, _ = w.Write([]byte(`{"base":"USD","rates":{"EUR":"0.8","JPY":"100"}}`))
+		}))
+		defer srv.Close()
+
+		client, err := NewRESTClient(newTestConf(srv, &types.RESTConfig{
+			Path:     "v2.0/rates/latest",
+			Query:    map[string]string{"symbols": "{symbols}"},
+			Auth:     types.AuthQuery,
+			AuthName: "apikey",
+			Batch:    true,
+			Price:    "rates.{symbol}",
+			Invert:   true,
+		}, map[string]string{"EUR-USD": "EUR", "JPY-USD": "JPY"}))
+		require.NoError(t, err)
+		require.True(t, client.KeyRequired())
+
+		prices, err := client.FetchPrice([]string{"EUR-USD", "JPY-USD"})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(prices))
+		require.Equal(t, "EUR-USD", prices[0].Symbol)
+		require.Equal(t, "1.25", prices[0].Price)
+		require.Equal(t, "0.01", prices[1].Price)
+	})
+
+	t.Run("batch request without any price parsed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"base":"USD","rates":{}}`))
+		}))
+		defer srv.Close()
+
+		client, err := NewRESTClient(newTestConf(srv, &types.RESTConfig{
+			Path:  "v2.0/rates/latest",
+			Batch: true,
+			Price: "rates.{symbol}",
+		}, map[string]string{"EUR-USD": "EUR", "JPY-USD": "JPY"}))
+		require.NoError(t, err)
+
+		_, err = client.FetchPrice([]string{"EUR-USD", "JPY-USD"})
+		require.ErrorIs(t, err, common.ErrDataNotAvailable)
+	})
+
+	t.Run("request per symbol with the key in the header", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			switch r.URL.Query().Get("symbol") {
+			case "NTNUSDT":
+				_, _ = w.Write([]byte(`[{"symbol":"NTNUSDT","price":"10.5","volume":"1000"}]`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer srv.Close()
+
+		client, err := NewRESTClient(newTestConf(srv, &types.RESTConfig{
+			Path:       "api/v3/ticker",
+			Query:      map[string]string{"symbol": "{symbol}"},
+			Auth:       types.AuthHeader,
+			AuthName:   "Authorization",
+			AuthPrefix: "Bearer ",
+			Price:      "[symbol={symbol}].price",
+			Volume:     "[symbol={symbol}].volume",
+		}, map[string]string{"NTN-USD": "NTNUSDT", "ATN-USD": "ATNUSDT"}))
+		require.NoError(t, err)
+
+		// the failed symbol is skipped.
+		prices, err := client.FetchPrice([]string{"NTN-USD", "ATN-USD"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(prices))
+		require.Equal(t, "NTN-USD", prices[0].Symbol)
+		require.Equal(t, "10.5", prices[0].Price)
+		require.Equal(t, "1000", prices[0].Volume)
+
+		_, err = client.FetchPrice([]string{"ATN-USD"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"strings"
+)
+
+// selectValue walks the JSON document by the selector. A selector is a dot separated path of the object fields, in
+// which an element of an array is selected by its index, e.g. data.0.price, or by the value of one of its fields, e.g.
+// data[symbol=EURUSD].price, and an array document is selected at the root by [symbol=EURUSD].price.
+func selectValue(doc interface{}, selector string) (interface{}, error) {
+	segments, err := splitSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	cur := doc
+	for _, seg := range segments {
+		name, filter := seg, ""
+		if i := strings.IndexByte(seg, '['); i >= 0 {
+			if !strings.HasSuffix(seg, "]") {
+				return nil, fmt.Errorf("invalid selector segment %q", seg)
+			}
+			name, filter = seg[:i], seg[i+1:len(seg)-1]
+		}
+
+		if name != "" {
+			if cur, err = selectField(cur, name); err != nil {
+				return nil, err
+			}
+		}
+		if filter != "" {
+			if cur, err = selectElement(cur, filter); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cur, nil
+}
+
+// splitSelector splits the selector by the dots which are not in the brackets, thus a filter value can carry dots.
+func splitSelector(selector string) ([]string, error) {
+	var segments []string
+	var depth, start int
+	for i, c := range selector {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, selector[start:i])
+				start = i + 1
+			}
+		}
+		if depth < 0 || depth > 1 {
+			return nil, fmt.Errorf("unbalanced brackets in selector %q", selector)
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets in selector %q", selector)
+	}
+	segments = append(segments, selector[start:])
+
+	for _, seg := range segments {
+		if seg == "" {
+			return nil, fmt.Errorf("empty segment in selector %q", selector)
+		}
+	}
+	return segments, nil
+}
+
+func selectField(cur interface{}, name string) (interface{}, error) {
+	switch v := cur.(type) {
+	case map[string]interface{}:
+		field, ok := v[name]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found", name)
+		}
+		return field, nil
+	case []interface{}:
+		return selectIndex(v, name)
+	default:
+		return nil, fmt.Errorf("cannot select field %q of a %T", name, cur)
+	}
+}
+
+func selectElement(cur interface{}, filter string) (interface{}, error) {
+	array, ok := cur.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot select element [%s] of a %T", filter, cur)
+	}
+
+	field, value, ok := strings.Cut(filter, "=")
+	if !ok {
+		return selectIndex(array, filter)
+	}
+	for _, e := range array {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if f, ok := obj[field]; ok && stringify(f) == value {
+			return obj, nil
+		}
+	}
+	return nil, fmt.Errorf("no element with %s=%s", field, value)
+}
+
+func selectIndex(array []interface{}, index string) (interface{}, error) {
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return nil, fmt.Errorf("invalid array index %q", index)
+	}
+	if i < 0 || i >= len(array) {
+		return nil, fmt.Errorf("array index %d out of range", i)
+	}
+	return array[i], nil
+}
+
+func stringify(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case json.Number:
+		return s.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toDecimal converts a selected value into a decimal, the providers quote the prices either in numbers or in strings.
+func toDecimal(v interface{}) (decimal.Decimal, error) {
+	switch n := v.(type) {
+	case string:
+		return decimal.NewFromString(n)
+	case json.Number:
+		return decimal.NewFromString(n.String())
+	default:
+		return decimal.Decimal{}, fmt.Errorf("cannot convert %T to decimal", v)
+	}
+}
@@ -46,6 +46,13 @@ func (bi *SIMClient) KeyRequired() bool {
 	return false
 }
 
+func (bi *SIMClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeSimulated,
+		QuoteCurrencies: []string{"USD"},
+	}
+}
+
 func (bi *SIMClient) FetchPrice(symbols []string) (common.Prices, error) {
 	var prices common.Prices
 	u, err := bi.buildURL(symbols)
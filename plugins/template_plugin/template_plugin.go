@@ -125,6 +125,9 @@ func (g *TemplatePlugin) State() (types.PluginState, error) {
 	state.KeyRequired = g.client.KeyRequired()
 	state.Version = g.version
 	state.AvailableSymbols = symbols
+	if r, ok := g.client.(common.MetadataReporter); ok {
+		state.DataSourceMetadata = r.Metadata()
+	}
 
 	return state, nil
 }
@@ -196,6 +199,15 @@ func (tc *TemplateClient) KeyRequired() bool {
 	return false
 }
 
+// Metadata returns the metadata of the data source which is reported to the oracle server via the v2 protocol.
+func (tc *TemplateClient) Metadata() types.DataSourceMetadata {
+	return types.DataSourceMetadata{
+		SourceType:      types.SourceTypeSimulated,
+		QuoteCurrencies: []string{"USD"},
+		// set MinRefreshInterval and RateLimit if your data provider publishes them.
+	}
+}
+
 // FetchPrice is the function fetch prices of the available symbols from data vendor.
 func (tc *TemplateClient) FetchPrice(symbols []string) (common.Prices, error) {
 	// todo: implement this function by plugin developer.
@@ -298,6 +310,9 @@ func main() {
 
 	plugin.Serve(&plugin.ServeConfig{
 		HandshakeConfig: types.HandshakeConfig,
-		Plugins:         pluginMap,
+		VersionedPlugins: map[int]plugin.PluginSet{
+			types.ProtocolV1: pluginMap,
+			types.ProtocolV2: pluginMap,
+		},
 	})
 }
@@ -0,0 +1,187 @@
+package roundstore
+
+import (
+	"autonity-oracle/types"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	roundFilePrefix = "round-"
+	roundFileSuffix = ".json"
+)
+
+// roundRecord is the on-disk layout of a types.RoundData, the transaction is kept in its binary encoding since the JSON
+// encoding of a transaction requires all the signature fields to be presented on decoding.
+type roundRecord struct {
+	RoundID        uint64                         `json:"round"`
+	Tx             hexutil.Bytes                  `json:"tx,omitempty"`
+	Salt           *big.Int                       `json:"salt"`
+	CommitmentHash common.Hash                    `json:"commitmentHash"`
+	Prices         types.PriceBySymbol            `json:"prices"`
+	Symbols        []string                       `json:"symbols"`
+	Sources        map[string]types.PriceByPlugin `json:"sources,omitempty"`
+	Derivations    map[string][]string            `json:"derivations,omitempty"`
+}
+
+// RoundStore persists the round data of the oracle server under a data directory, one file per round, thus the salt
+// and the prices of a committed round can survive a restart of the oracle server for the reveal in the next round.
+type RoundStore struct {
+	lock sync.Mutex
+	dir  string
+}
+
+func NewRoundStore(dir string) (*RoundStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &RoundStore{dir: dir}, nil
+}
+
+// Save writes the round data into the store, the file is written into a temporary file first and then be renamed, thus
+// a crash in the middle of a write cannot leave a corrupted round file behind.
+func (rs *RoundStore) Save(data *types.RoundData) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	record := roundRecord{
+		RoundID:        data.RoundID,
+		Salt:           data.Salt,
+		CommitmentHash: data.CommitmentHash,
+		Prices:         data.Prices,
+		Symbols:        data.Symbols,
+		Sources:        data.Sources,
+		Derivations:    data.Derivations,
+	}
+
+	if data.Tx != nil {
+		raw, err := data.Tx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		record.Tx = raw
+	}
+
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	tmp := rs.roundFile(data.RoundID) + ".tmp"
+	if err = os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, rs.roundFile(data.RoundID))
+}
+
+// Load returns all the round data saved in the store indexed by round ID.
+func (rs *RoundStore) Load() (map[uint64]*types.RoundData, error) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rounds, err := rs.listRounds()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64]*types.RoundData)
+	for _, round := range rounds {
+		content, err := os.ReadFile(rs.roundFile(round))
+		if err != nil {
+			return nil, err
+		}
+
+		var record roundRecord
+		if err = json.Unmarshal(content, &record); err != nil {
+			return nil, fmt.Errorf("corrupted round data of round %d: %w", round, err)
+		}
+
+		data := &types.RoundData{
+			RoundID:        record.RoundID,
+			Salt:           record.Salt,
+			CommitmentHash: record.CommitmentHash,
+			Prices:         record.Prices,
+			Symbols:        record.Symbols,
+			Sources:        record.Sources,
+			Derivations:    record.Derivations,
+		}
+
+		if len(record.Tx) != 0 {
+			tx := new(tp.Transaction)
+			if err = tx.UnmarshalBinary(record.Tx); err != nil {
+				return nil, fmt.Errorf("corrupted transaction of round %d: %w", round, err)
+			}
+			data.Tx = tx
+		}
+		result[round] = data
+	}
+
+	return result, nil
+}
+
+// Delete removes the round data of the round, it is not an error if the round is not in the store.
+func (rs *RoundStore) Delete(round uint64) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	if err := os.Remove(rs.roundFile(round)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Prune removes the round data of those rounds which are less than or equal to the offset.
+func (rs *RoundStore) Prune(offset uint64) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rounds, err := rs.listRounds()
+	if err != nil {
+		return err
+	}
+
+	for _, round := range rounds {
+		if round > offset {
+			continue
+		}
+		if err = os.Remove(rs.roundFile(round)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs *RoundStore) roundFile(round uint64) string {
+	return filepath.Join(rs.dir, fmt.Sprintf("%s%d%s", roundFilePrefix, round, roundFileSuffix))
+}
+
+func (rs *RoundStore) listRounds() ([]uint64, error) {
+	files, err := os.ReadDir(rs.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rounds []uint64
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasPrefix(name, roundFilePrefix) || !strings.HasSuffix(name, roundFileSuffix) {
+			continue
+		}
+
+		round, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, roundFilePrefix), roundFileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds, nil
+}
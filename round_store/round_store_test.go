@@ -0,0 +1,86 @@
+package roundstore
+
+import (
+	"autonity-oracle/types"
+	"github.com/ethereum/go-ethereum/common"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestRoundStore(t *testing.T) {
+	t.Run("save and load round data", func(t *testing.T) {
+		store, err := NewRoundStore(t.TempDir())
+		require.NoError(t, err)
+
+		tx := tp.NewTx(&tp.DynamicFeeTx{ChainID: new(big.Int).SetUint64(1000), Nonce: 1})
+		data := &types.RoundData{
+			RoundID:        10,
+			Tx:             tx,
+			Salt:           new(big.Int).SetUint64(123456789),
+			CommitmentHash: common.HexToHash("0x01"),
+			Prices: types.PriceBySymbol{"NTN-USD": types.Price{
+				Timestamp: 100,
+				Symbol:    "NTN-USD",
+				Price:     decimal.RequireFromString("10.01"),
+			}},
+			Symbols:     []string{"NTN-USD", "ATN-USD"},
+			Sources:     map[string]types.PriceByPlugin{"NTN-USD": {"template_plugin": decimal.RequireFromString("10.02")}},
+			Derivations: map[string][]string{"ATN-USD": {"ATN-NTN", "NTN-USD"}},
+		}
+		require.NoError(t, store.Save(data))
+
+		loaded, err := store.Load()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(loaded))
+		rd := loaded[10]
+		require.Equal(t, data.RoundID, rd.RoundID)
+		require.Equal(t, tx.Hash(), rd.Tx.Hash())
+		require.Equal(t, 0, data.Salt.Cmp(rd.Salt))
+		require.Equal(t, data.CommitmentHash, rd.CommitmentHash)
+		require.Equal(t, data.Symbols, rd.Symbols)
+		require.Equal(t, true, data.Prices["NTN-USD"].Price.Equal(rd.Prices["NTN-USD"].Price))
+		require.Equal(t, true, rd.Sources["NTN-USD"]["template_plugin"].Equal(decimal.RequireFromString("10.02")))
+		require.Equal(t, data.Derivations, rd.Derivations)
+	})
+
+	t.Run("delete round data", func(t *testing.T) {
+		store, err := NewRoundStore(t.TempDir())
+		require.NoError(t, err)
+
+		require.NoError(t, store.Save(&types.RoundData{RoundID: 1, Salt: big.NewInt(1)}))
+		require.NoError(t, store.Save(&types.RoundData{RoundID: 2, Salt: big.NewInt(2)}))
+		require.NoError(t, store.Delete(1))
+		require.NoError(t, store.Delete(3))
+
+		loaded, err := store.Load()
+		require.NoError(t, err)
+		require.Equal(t, 1, len(loaded))
+		require.Equal(t, 0, loaded[2].Salt.Cmp(big.NewInt(2)))
+	})
+
+	t.Run("prune round data", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewRoundStore(dir)
+		require.NoError(t, err)
+
+		for rd := uint64(1); rd <= 20; rd++ {
+			require.NoError(t, store.Save(&types.RoundData{RoundID: rd, Salt: big.NewInt(1)}))
+		}
+
+		require.NoError(t, store.Prune(20-types.MaxBufferedRounds))
+
+		// reopen the store to make sure the pruning is persisted.
+		store, err = NewRoundStore(dir)
+		require.NoError(t, err)
+		loaded, err := store.Load()
+		require.NoError(t, err)
+		require.Equal(t, types.MaxBufferedRounds, len(loaded))
+		_, ok := loaded[10]
+		require.Equal(t, false, ok)
+		_, ok = loaded[11]
+		require.Equal(t, true, ok)
+	})
+}
@@ -0,0 +1,192 @@
+package signer
+
+import (
+	"autonity-oracle/types"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+	"time"
+)
+
+// The types of signers of the oracle server account.
+const (
+	Keystore = "keystore" // the key is decrypted from the keystore file, and is kept in the memory of the oracle server.
+	Clef     = "clef"     // the transactions are signed by a Clef compatible external signer via IPC or HTTP.
+	RPC      = "rpc"      // the transactions are signed by a JSON-RPC service via eth_signTransaction.
+)
+
+var (
+	SignTimeout = 10 * time.Second
+
+	ErrUnknownSigner  = errors.New("unknown signer type")
+	ErrMissingKey     = errors.New("the key of the keystore signer is missing")
+	ErrInvalidSigning = errors.New("the signed transaction does not match the transaction to be signed")
+)
+
+// NewSigner returns the signer of the type set in the oracle server configuration.
+func NewSigner(conf *types.OracleServiceConfig) (types.Signer, error) {
+	switch conf.SignerType {
+	case Keystore, "":
+		if conf.Key == nil {
+			return nil, ErrMissingKey
+		}
+		return NewKeystoreSigner(conf.Key), nil
+	case Clef:
+		return NewClefSigner(conf.SignerURL, conf.SignerAddress)
+	case RPC:
+		return NewRPCSigner(conf.SignerURL, conf.SignerAddress)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSigner, conf.SignerType)
+	}
+}
+
+// KeystoreSigner signs the transactions with the key decrypted from the keystore file.
+type KeystoreSigner struct {
+	key *keystore.Key
+}
+
+func NewKeystoreSigner(key *keystore.Key) *KeystoreSigner {
+	return &KeystoreSigner{key: key}
+}
+
+func (s *KeystoreSigner) Address() common.Address {
+	return s.key.Address
+}
+
+func (s *KeystoreSigner) SignTx(tx *tp.Transaction, chainID *big.Int) (*tp.Transaction, error) {
+	return tp.SignTx(tx, tp.LatestSignerForChainID(chainID), s.key.PrivateKey)
+}
+
+// ClefSigner signs the transactions via the account_signTransaction API of a Clef compatible external signer, the
+// endpoint is either the path of the IPC socket or an HTTP URL.
+type ClefSigner struct {
+	account accounts.Account
+	signer  *external.ExternalSigner
+}
+
+func NewClefSigner(endpoint string, address common.Address) (*ClefSigner, error) {
+	signer, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ClefSigner{account: accounts.Account{Address: address}, signer: signer}, nil
+}
+
+func (s *ClefSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *ClefSigner) SignTx(tx *tp.Transaction, chainID *big.Int) (*tp.Transaction, error) {
+	signed, err := s.signer.SignTx(s.account, tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return verifySignedTx(tx, signed, chainID, s.account.Address)
+}
+
+// RPCSigner signs the transactions via the eth_signTransaction API of a JSON-RPC service, for example a node with the
+// account unlocked or a remote signing service.
+type RPCSigner struct {
+	address common.Address
+	client  *rpc.Client
+}
+
+func NewRPCSigner(endpoint string, address common.Address) (*RPCSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCSigner{address: address, client: client}, nil
+}
+
+func (s *RPCSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *RPCSigner) SignTx(tx *tp.Transaction, chainID *big.Int) (*tp.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	if tx.Type() == tp.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SignTimeout)
+	defer cancel()
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, "eth_signTransaction", &args); err != nil {
+		return nil, err
+	}
+
+	raw, err := decodeSignResult(result)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := new(tp.Transaction)
+	if err = signed.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return verifySignedTx(tx, signed, chainID, s.address)
+}
+
+// decodeSignResult returns the raw signed transaction of the eth_signTransaction result, it is either the object of
+// {"raw": ..., "tx": ...} returned by the Ethereum nodes, or the hex string of the raw transaction.
+func decodeSignResult(result json.RawMessage) (hexutil.Bytes, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err == nil {
+		return raw, nil
+	}
+
+	var res struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(result, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Raw) == 0 {
+		return nil, fmt.Errorf("no raw transaction in the sign result")
+	}
+	return res.Raw, nil
+}
+
+// verifySignedTx checks that a remote signer signed the same transaction by the expected account, thus a misconfigured
+// or a compromised signer cannot send anything else on behalf of the oracle server.
+func verifySignedTx(tx, signed *tp.Transaction, chainID *big.Int, address common.Address) (*tp.Transaction, error) {
+	signer := tp.LatestSignerForChainID(chainID)
+	if signer.Hash(tx) != signer.Hash(signed) {
+		return nil, ErrInvalidSigning
+	}
+
+	sender, err := tp.Sender(signer, signed)
+	if err != nil {
+		return nil, err
+	}
+	if sender != address {
+		return nil, fmt.Errorf("%w: signed by %s rather than %s", ErrInvalidSigning, sender, address)
+	}
+	return signed, nil
+}
@@ -0,0 +1,134 @@
+package signer
+
+import (
+	"autonity-oracle/types"
+	"crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	tp "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeSigner serves account_signTransaction of Clef and eth_signTransaction of the Ethereum nodes.
+type fakeSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+	tamper  bool // sign a different transaction than the requested one.
+}
+
+func (f *fakeSigner) sign(args apitypes.SendTxArgs) (*tp.Transaction, error) {
+	if f.tamper {
+		args.Nonce++
+	}
+	return tp.SignTx(args.ToTransaction(), tp.LatestSignerForChainID(f.chainID), f.key)
+}
+
+type accountAPI struct{ *fakeSigner }
+
+func (a *accountAPI) Version() string {
+	return "6.0.0"
+}
+
+func (a *accountAPI) SignTransaction(args apitypes.SendTxArgs) (map[string]interface{}, error) {
+	tx, err := a.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": tx}, nil
+}
+
+type ethAPI struct{ *fakeSigner }
+
+func (e *ethAPI) SignTransaction(args apitypes.SendTxArgs) (hexutil.Bytes, error) {
+	tx, err := e.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+func newFakeSignerServer(t *testing.T, f *fakeSigner) string {
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("account", &accountAPI{f}))
+	require.NoError(t, srv.RegisterName("eth", &ethAPI{f}))
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		httpSrv.Close()
+		srv.Stop()
+	})
+	return httpSrv.URL
+}
+
+func TestSigners(t *testing.T) {
+	chainID := big.NewInt(65000000)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x47e9Fbef8C83A1714F1951F142132E6e90F5fa5D")
+	tx := tp.NewTx(&tp.DynamicFeeTx{Nonce: 3, To: &to, Gas: 3000000, GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1000), Value: big.NewInt(0), Data: []byte{0x1, 0x2}})
+
+	checkSigned := func(t *testing.T, s types.Signer) {
+		require.Equal(t, address, s.Address())
+		signed, err := s.SignTx(tx, chainID)
+		require.NoError(t, err)
+		sender, err := tp.Sender(tp.LatestSignerForChainID(chainID), signed)
+		require.NoError(t, err)
+		require.Equal(t, address, sender)
+		require.Equal(t, tx.Nonce(), signed.Nonce())
+		require.Equal(t, tx.Data(), signed.Data())
+	}
+
+	t.Run("keystore signer", func(t *testing.T) {
+		s, err := NewSigner(&types.OracleServiceConfig{SignerType: Keystore, Key: &keystore.Key{Address: address, PrivateKey: key}})
+		require.NoError(t, err)
+		checkSigned(t, s)
+
+		_, err = NewSigner(&types.OracleServiceConfig{SignerType: Keystore})
+		require.ErrorIs(t, err, ErrMissingKey)
+		_, err = NewSigner(&types.OracleServiceConfig{SignerType: "hsm"})
+		require.ErrorIs(t, err, ErrUnknownSigner)
+	})
+
+	t.Run("clef signer", func(t *testing.T) {
+		url := newFakeSignerServer(t, &fakeSigner{key: key, chainID: chainID})
+		s, err := NewSigner(&types.OracleServiceConfig{SignerType: Clef, SignerURL: url, SignerAddress: address})
+		require.NoError(t, err)
+		checkSigned(t, s)
+	})
+
+	t.Run("rpc signer", func(t *testing.T) {
+		url := newFakeSignerServer(t, &fakeSigner{key: key, chainID: chainID})
+		s, err := NewSigner(&types.OracleServiceConfig{SignerType: RPC, SignerURL: url, SignerAddress: address})
+		require.NoError(t, err)
+		checkSigned(t, s)
+	})
+
+	t.Run("reject the transaction signed by a misbehaving signer", func(t *testing.T) {
+		url := newFakeSignerServer(t, &fakeSigner{key: key, chainID: chainID, tamper: true})
+		s, err := NewRPCSigner(url, address)
+		require.NoError(t, err)
+		_, err = s.SignTx(tx, chainID)
+		require.ErrorIs(t, err, ErrInvalidSigning)
+
+		// the transaction is signed by another account.
+		other, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		url = newFakeSignerServer(t, &fakeSigner{key: other, chainID: chainID})
+		c, err := NewClefSigner(url, address)
+		require.NoError(t, err)
+		_, err = c.SignTx(tx, chainID)
+		require.ErrorIs(t, err, ErrInvalidSigning)
+	})
+}
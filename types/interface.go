@@ -12,13 +12,18 @@ import (
 
 // Dialer to help the dial function be mocked in oracle server's unit test.
 type Dialer interface {
-	Dial(rawurl string) (*ethclient.Client, error)
+	Dial(rawurl string) (Blockchain, error)
 }
 
 type L1Dialer struct{}
 
-func (ws *L1Dialer) Dial(rawurl string) (*ethclient.Client, error) {
-	return ethclient.Dial(rawurl)
+func (ws *L1Dialer) Dial(rawurl string) (Blockchain, error) {
+	client, err := ethclient.Dial(rawurl)
+	if err != nil {
+		// do not wrap a nil client into the interface.
+		return nil, err
+	}
+	return client, nil
 }
 
 // Blockchain is the L1 interface to help to mock the L1 for the unit test in oracle server.
@@ -32,6 +37,9 @@ type Blockchain interface {
 	PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error)
 	// PendingCallContract executes an Ethereum contract call against the pending state.
 	PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error)
+	// NonceAt returns the account nonce of the given account. The block number can be nil, in which case the nonce is
+	// taken from the latest known block.
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
 	// PendingNonceAt retrieves the current pending nonce associated with an account.
 	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
 	// SuggestGasPrice retrieves the currently suggested gas price to allow a timely
@@ -76,3 +84,17 @@ type Blockchain interface {
 type SampleEventSubscriber interface {
 	WatchSampleEvent(sink chan<- *SampleEvent) event.Subscription
 }
+
+// Signer signs the transactions of the oracle server account, thus the private key can be kept out of the oracle server.
+type Signer interface {
+	// Address returns the address of the oracle server account.
+	Address() common.Address
+	// SignTx signs the transaction with the EIP-155 replay protection of the chain.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// FeeStrategy picks the gas settings of the vote transactions.
+type FeeStrategy interface {
+	// Fees returns the gas settings of a vote sent in the vote period between the start and the end height of a round.
+	Fees(ctx context.Context, client Blockchain, msg ethereum.CallMsg, start, end uint64) (*Fees, error)
+}
@@ -13,7 +13,6 @@ import (
 	ethereum "github.com/ethereum/go-ethereum"
 	common "github.com/ethereum/go-ethereum/common"
 	types0 "github.com/ethereum/go-ethereum/core/types"
-	ethclient "github.com/ethereum/go-ethereum/ethclient"
 	event "github.com/ethereum/go-ethereum/event"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -42,10 +41,10 @@ func (m *MockDialer) EXPECT() *MockDialerMockRecorder {
 }
 
 // Dial mocks base method.
-func (m *MockDialer) Dial(rawurl string) (*ethclient.Client, error) {
+func (m *MockDialer) Dial(rawurl string) (types.Blockchain, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Dial", rawurl)
-	ret0, _ := ret[0].(*ethclient.Client)
+	ret0, _ := ret[0].(types.Blockchain)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -211,6 +210,21 @@ func (mr *MockBlockchainMockRecorder) HeaderByNumber(ctx, number interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByNumber", reflect.TypeOf((*MockBlockchain)(nil).HeaderByNumber), ctx, number)
 }
 
+// NonceAt mocks base method.
+func (m *MockBlockchain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NonceAt", ctx, account, blockNumber)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NonceAt indicates an expected call of NonceAt.
+func (mr *MockBlockchainMockRecorder) NonceAt(ctx, account, blockNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NonceAt", reflect.TypeOf((*MockBlockchain)(nil).NonceAt), ctx, account, blockNumber)
+}
+
 // PendingCallContract mocks base method.
 func (m *MockBlockchain) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -412,3 +426,93 @@ func (mr *MockSampleEventSubscriberMockRecorder) WatchSampleEvent(sink interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchSampleEvent", reflect.TypeOf((*MockSampleEventSubscriber)(nil).WatchSampleEvent), sink)
 }
+
+// MockSigner is a mock of Signer interface.
+type MockSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignerMockRecorder
+}
+
+// MockSignerMockRecorder is the mock recorder for MockSigner.
+type MockSignerMockRecorder struct {
+	mock *MockSigner
+}
+
+// NewMockSigner creates a new mock instance.
+func NewMockSigner(ctrl *gomock.Controller) *MockSigner {
+	mock := &MockSigner{ctrl: ctrl}
+	mock.recorder = &MockSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSigner) EXPECT() *MockSignerMockRecorder {
+	return m.recorder
+}
+
+// Address mocks base method.
+func (m *MockSigner) Address() common.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Address")
+	ret0, _ := ret[0].(common.Address)
+	return ret0
+}
+
+// Address indicates an expected call of Address.
+func (mr *MockSignerMockRecorder) Address() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Address", reflect.TypeOf((*MockSigner)(nil).Address))
+}
+
+// SignTx mocks base method.
+func (m *MockSigner) SignTx(tx *types0.Transaction, chainID *big.Int) (*types0.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignTx", tx, chainID)
+	ret0, _ := ret[0].(*types0.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignTx indicates an expected call of SignTx.
+func (mr *MockSignerMockRecorder) SignTx(tx, chainID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignTx", reflect.TypeOf((*MockSigner)(nil).SignTx), tx, chainID)
+}
+
+// MockFeeStrategy is a mock of FeeStrategy interface.
+type MockFeeStrategy struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeeStrategyMockRecorder
+}
+
+// MockFeeStrategyMockRecorder is the mock recorder for MockFeeStrategy.
+type MockFeeStrategyMockRecorder struct {
+	mock *MockFeeStrategy
+}
+
+// NewMockFeeStrategy creates a new mock instance.
+func NewMockFeeStrategy(ctrl *gomock.Controller) *MockFeeStrategy {
+	mock := &MockFeeStrategy{ctrl: ctrl}
+	mock.recorder = &MockFeeStrategyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeeStrategy) EXPECT() *MockFeeStrategyMockRecorder {
+	return m.recorder
+}
+
+// Fees mocks base method.
+func (m *MockFeeStrategy) Fees(ctx context.Context, client types.Blockchain, msg ethereum.CallMsg, start, end uint64) (*types.Fees, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fees", ctx, client, msg, start, end)
+	ret0, _ := ret[0].(*types.Fees)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fees indicates an expected call of Fees.
+func (mr *MockFeeStrategyMockRecorder) Fees(ctx, client, msg, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fees", reflect.TypeOf((*MockFeeStrategy)(nil).Fees), ctx, client, msg, start, end)
+}
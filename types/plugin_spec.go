@@ -1,7 +1,13 @@
 package types
 
 import (
+	"autonity-oracle/types/proto"
+	"context"
+	"fmt"
 	"github.com/hashicorp/go-plugin"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"math"
 	"net/rpc"
 )
 
@@ -18,6 +24,28 @@ var HandshakeConfig = plugin.HandshakeConfig{
 	MagicCookieValue: "hello",
 }
 
+// EnvPluginConfFD is the env variable which carries the file descriptor of the pipe that the oracle server passes the
+// plugin configuration in JSON through, thus the configuration does not show in the env of the plugin process.
+const EnvPluginConfFD = "PLUGIN_CONF_FD"
+
+// The versions of the plugin protocol, the highest version supported by both the oracle server and the plugin is
+// negotiated on the handshake. Since v2, the plugins report the metadata of their data source in the State.
+const (
+	ProtocolV1 = 1
+	ProtocolV2 = 2
+)
+
+// VersionedPlugins are the plugin sets by the protocol versions negotiated with the plugins. The adapter of each version
+// can be served via net/rpc or via gRPC, it is decided by the plugin, thus the plugins can be implemented in any
+// language that go-plugin supports with the protobuf definitions in types/proto.
+var VersionedPlugins = map[int]plugin.PluginSet{
+	ProtocolV1: {"adapter": &AdapterPlugin{}},
+	ProtocolV2: {"adapter": &AdapterPlugin{}},
+}
+
+// AllowedProtocols are the transports of the plugin protocol.
+var AllowedProtocols = []plugin.Protocol{plugin.ProtocolNetRPC, plugin.ProtocolGRPC}
+
 // PluginPriceReport is the returned data samples from adapters which carry the prices and those symbols of no data if
 // there are any unrecognisable symbols from the data source side.
 type PluginPriceReport struct {
@@ -26,11 +54,28 @@ type PluginPriceReport struct {
 }
 
 // PluginState is the returned data when the oracle host want to initialise the plugin with basic information: version,
-// and available symbols that the data source support.
+// and available symbols that the data source support, and the metadata of the data source since the v2 protocol.
 type PluginState struct {
 	KeyRequired      bool
 	Version          string
 	AvailableSymbols []string
+	DataSourceMetadata
+}
+
+// The types of data sources.
+const (
+	SourceTypeForex     = "forex"
+	SourceTypeCrypto    = "crypto"
+	SourceTypeSimulated = "simulated"
+)
+
+// DataSourceMetadata is the metadata of the data source declared by a plugin of the v2 protocol.
+type DataSourceMetadata struct {
+	SourceType         string   // forex, crypto or simulated.
+	QuoteCurrencies    []string // the quote currencies supported by the data source, e.g. USD.
+	MinRefreshInterval int      // the minimum interval in seconds to fetch data from the data source, 0 if it is not declared.
+	RateLimit          int      // the budget of requests per hour to the data source, 0 if it is not declared.
+	Confidence         uint8    // the self-declared confidence score of the data, from 0 to 100, 0 if it is not declared.
 }
 
 // Adapter is the interface that we're exposing as a plugin.
@@ -86,8 +131,101 @@ func (s *AdapterRPCServer) State(args interface{}, resp *PluginState) error {
 	return err
 }
 
+// AdapterGRPCClient is an implementation that talks over gRPC client.
+type AdapterGRPCClient struct{ client proto.AdapterClient }
+
+func (g *AdapterGRPCClient) FetchPrices(symbols []string) (PluginPriceReport, error) {
+	var report PluginPriceReport
+	resp, err := g.client.FetchPrices(context.Background(), &proto.FetchPricesRequest{Symbols: symbols})
+	if err != nil {
+		return report, err
+	}
+
+	for _, p := range resp.Prices {
+		price, err := decimal.NewFromString(p.Price)
+		if err != nil {
+			return report, err
+		}
+
+		var volume decimal.Decimal
+		if p.Volume != "" {
+			if volume, err = decimal.NewFromString(p.Volume); err != nil {
+				return report, err
+			}
+		}
+
+		report.Prices = append(report.Prices, Price{Timestamp: p.Timestamp, Symbol: p.Symbol, Price: price, Volume: volume})
+	}
+	report.UnRecognizableSymbols = resp.UnRecognizableSymbols
+	return report, nil
+}
+
+func (g *AdapterGRPCClient) State() (PluginState, error) {
+	var state PluginState
+	resp, err := g.client.State(context.Background(), &proto.StateRequest{})
+	if err != nil {
+		return state, err
+	}
+
+	state.KeyRequired = resp.KeyRequired
+	state.Version = resp.Version
+	state.AvailableSymbols = resp.AvailableSymbols
+	state.SourceType = resp.SourceType
+	state.QuoteCurrencies = resp.QuoteCurrencies
+	state.MinRefreshInterval = int(resp.MinRefreshInterval)
+	state.RateLimit = int(resp.RateLimit)
+	if resp.Confidence > math.MaxUint8 {
+		return state, fmt.Errorf("invalid confidence score %d", resp.Confidence)
+	}
+	state.Confidence = uint8(resp.Confidence)
+	return state, nil
+}
+
+// AdapterGRPCServer is the gRPC server that AdapterGRPCClient talks to.
+type AdapterGRPCServer struct {
+	proto.UnimplementedAdapterServer
+	// This is the real implementation
+	Impl Adapter
+}
+
+func (s *AdapterGRPCServer) FetchPrices(ctx context.Context, req *proto.FetchPricesRequest) (*proto.FetchPricesResponse, error) {
+	report, err := s.Impl.FetchPrices(req.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &proto.FetchPricesResponse{UnRecognizableSymbols: report.UnRecognizableSymbols}
+	for _, p := range report.Prices {
+		price := &proto.Price{Timestamp: p.Timestamp, Symbol: p.Symbol, Price: p.Price.String()}
+		if !p.Volume.IsZero() {
+			price.Volume = p.Volume.String()
+		}
+		resp.Prices = append(resp.Prices, price)
+	}
+	return resp, nil
+}
+
+func (s *AdapterGRPCServer) State(ctx context.Context, req *proto.StateRequest) (*proto.StateResponse, error) {
+	state, err := s.Impl.State()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.StateResponse{
+		KeyRequired:        state.KeyRequired,
+		Version:            state.Version,
+		AvailableSymbols:   state.AvailableSymbols,
+		SourceType:         state.SourceType,
+		QuoteCurrencies:    state.QuoteCurrencies,
+		MinRefreshInterval: uint32(state.MinRefreshInterval),
+		RateLimit:          uint32(state.RateLimit),
+		Confidence:         uint32(state.Confidence),
+	}, nil
+}
+
 // AdapterPlugin is the unified implementation of plugins, all the 3rd parties plugins need to inject their
-// implementation by using this structure in their source code.
+// implementation by using this structure in their source code. It can be served via net/rpc, or via gRPC by setting
+// the GRPCServer of the plugin.ServeConfig.
 type AdapterPlugin struct {
 	// Impl Injection
 	Impl Adapter
@@ -100,3 +238,12 @@ func (p *AdapterPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
 func (AdapterPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
 	return &AdapterRPCClient{client: c}, nil
 }
+
+func (p *AdapterPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	proto.RegisterAdapterServer(s, &AdapterGRPCServer{Impl: p.Impl})
+	return nil
+}
+
+func (p *AdapterPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &AdapterGRPCClient{client: proto.NewAdapterClient(c)}, nil
+}
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"errors"
+	"github.com/hashicorp/go-plugin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type fakeAdapter struct {
+	err error
+}
+
+func (f *fakeAdapter) FetchPrices(symbols []string) (PluginPriceReport, error) {
+	if f.err != nil {
+		return PluginPriceReport{}, f.err
+	}
+	return PluginPriceReport{
+		Prices: []Price{
+			{Timestamp: 100, Symbol: symbols[0], Price: decimal.RequireFromString("1.0852")},
+			{Timestamp: 100, Symbol: symbols[1], Price: decimal.RequireFromString("10.01"), Volume: decimal.RequireFromString("350.5")},
+		},
+		UnRecognizableSymbols: symbols[2:],
+	}, nil
+}
+
+func (f *fakeAdapter) State() (PluginState, error) {
+	return PluginState{
+		KeyRequired:      true,
+		Version:          "v0.0.1",
+		AvailableSymbols: []string{"EUR-USD", "NTN-USD"},
+		DataSourceMetadata: DataSourceMetadata{
+			SourceType:         SourceTypeForex,
+			QuoteCurrencies:    []string{"USD"},
+			MinRefreshInterval: 10,
+			RateLimit:          1000,
+			Confidence:         80,
+		},
+	}, f.err
+}
+
+func TestAdapterPlugin(t *testing.T) {
+	symbols := []string{"EUR-USD", "NTN-USD", "SEK-USD"}
+	checkAdapter := func(t *testing.T, adapter Adapter) {
+		state, err := adapter.State()
+		require.NoError(t, err)
+		require.Equal(t, true, state.KeyRequired)
+		require.Equal(t, "v0.0.1", state.Version)
+		require.Equal(t, []string{"EUR-USD", "NTN-USD"}, state.AvailableSymbols)
+		require.Equal(t, SourceTypeForex, state.SourceType)
+		require.Equal(t, []string{"USD"}, state.QuoteCurrencies)
+		require.Equal(t, 10, state.MinRefreshInterval)
+		require.Equal(t, 1000, state.RateLimit)
+		require.Equal(t, uint8(80), state.Confidence)
+
+		report, err := adapter.FetchPrices(symbols)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(report.Prices))
+		require.Equal(t, "EUR-USD", report.Prices[0].Symbol)
+		require.Equal(t, int64(100), report.Prices[0].Timestamp)
+		require.True(t, report.Prices[0].Price.Equal(decimal.RequireFromString("1.0852")))
+		require.True(t, report.Prices[0].Volume.IsZero())
+		require.True(t, report.Prices[1].Volume.Equal(decimal.RequireFromString("350.5")))
+		require.Equal(t, []string{"SEK-USD"}, report.UnRecognizableSymbols)
+	}
+
+	t.Run("net/rpc transport", func(t *testing.T) {
+		client, _ := plugin.TestPluginRPCConn(t, plugin.PluginSet{"adapter": &AdapterPlugin{Impl: &fakeAdapter{}}}, nil)
+		defer client.Close()
+
+		raw, err := client.Dispense("adapter")
+		require.NoError(t, err)
+		checkAdapter(t, raw.(Adapter))
+	})
+
+	t.Run("gRPC transport", func(t *testing.T) {
+		client, _ := plugin.TestPluginGRPCConn(t, plugin.PluginSet{"adapter": &AdapterPlugin{Impl: &fakeAdapter{}}})
+		defer client.Close()
+
+		raw, err := client.Dispense("adapter")
+		require.NoError(t, err)
+		checkAdapter(t, raw.(Adapter))
+	})
+
+	t.Run("gRPC transport with error", func(t *testing.T) {
+		client, _ := plugin.TestPluginGRPCConn(t, plugin.PluginSet{"adapter": &AdapterPlugin{Impl: &fakeAdapter{err: errors.New("rate limited")}}})
+		defer client.Close()
+
+		raw, err := client.Dispense("adapter")
+		require.NoError(t, err)
+		_, err = raw.(Adapter).FetchPrices(symbols)
+		require.ErrorContains(t, err, "rate limited")
+	})
+}
@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: types/proto/adapter.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// AdapterClient is the client API for Adapter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdapterClient interface {
+	FetchPrices(ctx context.Context, in *FetchPricesRequest, opts ...grpc.CallOption) (*FetchPricesResponse, error)
+	State(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*StateResponse, error)
+}
+
+type adapterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdapterClient(cc grpc.ClientConnInterface) AdapterClient {
+	return &adapterClient{cc}
+}
+
+func (c *adapterClient) FetchPrices(ctx context.Context, in *FetchPricesRequest, opts ...grpc.CallOption) (*FetchPricesResponse, error) {
+	out := new(FetchPricesResponse)
+	err := c.cc.Invoke(ctx, "/proto.Adapter/FetchPrices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adapterClient) State(ctx context.Context, in *StateRequest, opts ...grpc.CallOption) (*StateResponse, error) {
+	out := new(StateResponse)
+	err := c.cc.Invoke(ctx, "/proto.Adapter/State", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdapterServer is the server API for Adapter service.
+// All implementations must embed UnimplementedAdapterServer
+// for forward compatibility
+type AdapterServer interface {
+	FetchPrices(context.Context, *FetchPricesRequest) (*FetchPricesResponse, error)
+	State(context.Context, *StateRequest) (*StateResponse, error)
+	mustEmbedUnimplementedAdapterServer()
+}
+
+// UnimplementedAdapterServer must be embedded to have forward compatible implementations.
+type UnimplementedAdapterServer struct {
+}
+
+func (UnimplementedAdapterServer) FetchPrices(context.Context, *FetchPricesRequest) (*FetchPricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchPrices not implemented")
+}
+func (UnimplementedAdapterServer) State(context.Context, *StateRequest) (*StateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method State not implemented")
+}
+func (UnimplementedAdapterServer) mustEmbedUnimplementedAdapterServer() {}
+
+// UnsafeAdapterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdapterServer will
+// result in compilation errors.
+type UnsafeAdapterServer interface {
+	mustEmbedUnimplementedAdapterServer()
+}
+
+func RegisterAdapterServer(s grpc.ServiceRegistrar, srv AdapterServer) {
+	s.RegisterService(&Adapter_ServiceDesc, srv)
+}
+
+func _Adapter_FetchPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchPricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServer).FetchPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Adapter/FetchPrices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServer).FetchPrices(ctx, req.(*FetchPricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Adapter_State_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdapterServer).State(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Adapter/State",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdapterServer).State(ctx, req.(*StateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Adapter_ServiceDesc is the grpc.ServiceDesc for Adapter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Adapter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Adapter",
+	HandlerType: (*AdapterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchPrices",
+			Handler:    _Adapter_FetchPrices_Handler,
+		},
+		{
+			MethodName: "State",
+			Handler:    _Adapter_State_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "types/proto/adapter.proto",
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -11,6 +12,10 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/shopspring/decimal"
 	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
 )
 
 var (
@@ -21,6 +26,25 @@ var (
 	EnvPluginCof            = "PLUGIN_CONF"
 	EnvGasTipCap            = "GAS_TIP_CAP"
 	EnvLogLevel             = "LOG_LEVEL"
+	EnvDataDIR              = "DATA_DIR"
+	EnvMetrics              = "METRICS"
+	EnvMetricsAddr          = "METRICS_ADDR"
+	EnvAggregationConf      = "AGGREGATION_CONF"
+	EnvAdmin                = "ADMIN"
+	EnvAdminAddr            = "ADMIN_ADDR"
+	EnvPluginVerify         = "PLUGIN_VERIFY"
+	EnvSigner               = "SIGNER"
+	EnvSignerURL            = "SIGNER_URL"
+	EnvSignerAddress        = "SIGNER_ADDRESS"
+	EnvDryRun               = "DRY_RUN"
+	EnvGasStrategy          = "GAS_STRATEGY"
+	EnvGasTipMax            = "GAS_TIP_MAX"
+	EnvGasFeeCapMax         = "GAS_FEE_CAP_MAX"
+	EnvGasLimitMax          = "GAS_LIMIT_MAX"
+	EnvBalanceAlert         = "BALANCE_ALERT"
+	EnvBalanceRunway        = "BALANCE_RUNWAY"
+	EnvAlertWebhook         = "ALERT_WEBHOOK"
+	EnvAlertExec            = "ALERT_EXEC"
 	SimulatedPrice          = decimal.RequireFromString("11.11")
 	InvalidPrice            = new(big.Int).Sub(math.BigPow(2, 255), big.NewInt(1))
 	InvalidSalt             = big.NewInt(0)
@@ -30,9 +54,16 @@ var (
 
 	ErrPeerOnSync        = errors.New("l1 node is on peer sync")
 	ErrNoAvailablePrice  = errors.New("no available prices collected yet")
+	ErrStaleSample       = errors.New("the nearest sample is out of the max age window of the plugin")
 	ErrNoDataRound       = errors.New("no data collected at current round")
 	ErrNoSymbolsObserved = errors.New("no symbols observed from oracle contract")
 	ErrMissingServiceKey = errors.New("the key to access the data source is missing, please check the plugin config")
+	ErrRefreshTooLow     = errors.New("the refresh interval is lower than the data source allows, please check the plugin config")
+	ErrPluginNotFound    = errors.New("plugin not found")
+	ErrMissingChecksum   = errors.New("the checksum of the plugin binary is missing, please check the plugin config")
+	ErrServerBusy        = errors.New("oracle server is busy, please retry later")
+	ErrNoHealthyEndpoint = errors.New("no healthy L1 endpoint is reachable")
+	ErrConfNotDelivered  = errors.New("the configuration cannot be delivered to the plugin")
 )
 
 // MaxBufferedRounds is the number of round data to be buffered.
@@ -43,6 +74,7 @@ type Price struct {
 	Timestamp int64 // TS on when the data is being sampled in time's seconds since Jan 1 1970 (Unix time).
 	Symbol    string
 	Price     decimal.Decimal
+	Volume    decimal.Decimal // the optional trading volume behind the price, it is zero if the data source does not provide it.
 }
 
 // PriceBySymbol group the price by symbols.
@@ -57,16 +89,96 @@ type RoundData struct {
 	CommitmentHash common.Hash
 	Prices         PriceBySymbol
 	Symbols        []string
+	Sources        map[string]PriceByPlugin // the samples of the plugins aggregated into the prices, by symbols.
+	Derivations    map[string][]string      // the input symbols of the derived prices, by symbols.
+	Confirmed      bool                     // the vote is confirmed by the voted event of the oracle contract.
 }
 
+// Fees are the gas settings of a vote transaction, a nil fee cap is left to the transactor.
+type Fees struct {
+	GasLimit  uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// PriceByPlugin are the samples of a symbol by the plugins.
+type PriceByPlugin map[string]decimal.Decimal
+
 // OracleServiceConfig is the configuration of the oracle client.
 type OracleServiceConfig struct {
-	LoggingLevel   hclog.Level
-	GasTipCap      uint64
-	Key            *keystore.Key
-	AutonityWSUrl  string
-	PluginDIR      string
-	PluginConfFile string
+	LoggingLevel    hclog.Level
+	GasStrategy     string        // the gas fee strategy of the vote transactions, static or dynamic.
+	GasTipCap       uint64        // the tip of the static strategy, or the floor of the tip of the dynamic strategy.
+	GasTipMax       uint64        // the ceiling of the tip of the dynamic strategy, zero is no ceiling.
+	GasFeeCapMax    uint64        // the ceiling of the fee cap of the dynamic strategy, zero is no ceiling.
+	GasLimitMax     uint64        // the gas limit of the static strategy, or the ceiling of the estimated gas limit.
+	Key             *keystore.Key // the key decrypted from the keystore file, it is nil with a remote signer.
+	SignerType      string
+	SignerURL       string
+	SignerAddress   common.Address
+	AutonityWSUrl   string   // the endpoint connected on startup, it is the first one of AutonityWSUrls.
+	AutonityWSUrls  []string // the endpoints of the L1 network to fail over between.
+	PluginDIR       string
+	PluginConfFile  string
+	PluginVerify    bool
+	AggregationConf string
+	DataDIR         string
+	MetricsEnabled  bool
+	MetricsAddr     string
+	AdminEnabled    bool
+	AdminAddr       string
+	DryRun          bool   // compute the votes without submitting them, and compare them with the on-chain prices.
+	BalanceAlert    uint64 // the balance of the oracle server account to alert, in wei.
+	BalanceRunway   uint64 // the runway of the oracle server account to alert, in rounds.
+	AlertWebhook    string // the URL to post the alerts to.
+	AlertExec       string // the executable to run on the alerts.
+}
+
+// PluginInfo is the runtime state of a loaded plugin.
+type PluginInfo struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	Protocol  int       `json:"protocol"`
+	StartTime time.Time `json:"startTime"`
+	Exited    bool      `json:"exited"`
+}
+
+// RoundState is the state of the current round tracked by the oracle server.
+type RoundState struct {
+	Round        uint64 `json:"round"`
+	VotePeriod   uint64 `json:"votePeriod"`
+	SampleTS     uint64 `json:"sampleTS"`
+	SampleHeight uint64 `json:"sampleHeight"`
+}
+
+// SymbolDeviation compares the price computed in the dry-run mode with the on-chain price of a symbol.
+type SymbolDeviation struct {
+	Symbol    string          `json:"symbol"`
+	Shadow    decimal.Decimal `json:"shadow"`
+	OnChain   decimal.Decimal `json:"onChain"`
+	Deviation float64         `json:"deviation"` // the relative deviation of the shadow price from the on-chain price.
+}
+
+// AccuracyStats are the rolling statistics of the relative deviations of the voted prices of a symbol, or of the samples
+// of a plugin, from the on-chain aggregated prices.
+type AccuracyStats struct {
+	Name          string  `json:"name"`
+	Rounds        int     `json:"rounds"` // the scored rounds in the window.
+	Failed        int     `json:"failed"` // the rounds whose on-chain aggregation failed, it is only counted for symbols.
+	MeanDeviation float64 `json:"meanDeviation"`
+	MaxDeviation  float64 `json:"maxDeviation"`
+}
+
+// AccuracyReport is the accuracy of the votes by symbols and by plugins, the least accurate ones come first.
+type AccuracyReport struct {
+	Symbols []AccuracyStats `json:"symbols"`
+	Plugins []AccuracyStats `json:"plugins"`
+}
+
+// DeviationReport is the comparison of the votes computed in the dry-run mode with the on-chain prices of a round.
+type DeviationReport struct {
+	Round   uint64            `json:"round"`
+	Symbols []SymbolDeviation `json:"symbols"`
 }
 
 // JSONRPCMessage is the JSON spec to carry those data response from the binance data simulator.
@@ -86,10 +198,159 @@ type SampleEvent struct {
 
 // PluginConfig carry the configuration of plugins.
 type PluginConfig struct {
-	Name               string `json:"name" yaml:"name"`         // the name of the plugin binary.
-	Key                string `json:"key" yaml:"key"`           // the API key granted by your data provider to access their data API.
-	Scheme             string `json:"scheme" yaml:"scheme"`     // the data service scheme, http or https.
-	Endpoint           string `json:"endpoint" yaml:"endpoint"` // the data service endpoint url of the data provider.
-	Timeout            int    `json:"timeout" yaml:"timeout"`   // the timeout period in seconds that an API request is lasting for.
-	DataUpdateInterval int    `json:"refresh" yaml:"refresh"`   // the interval in seconds to fetch data from data provider due to rate limit.
+	Name               string            `json:"name" yaml:"name"`                 // the name of the plugin binary.
+	Key                string            `json:"key" yaml:"key"`                   // the API key granted by your data provider to access their data API.
+	KeyFile            string            `json:"-" yaml:"key_file"`                // the file or the secrets directory to read the API key from, instead of setting it in the key.
+	Scheme             string            `json:"scheme" yaml:"scheme"`             // the data service scheme, http or https.
+	Endpoint           string            `json:"endpoint" yaml:"endpoint"`         // the data service endpoint url of the data provider.
+	Timeout            int               `json:"timeout" yaml:"timeout"`           // the timeout period in seconds that an API request is lasting for.
+	DataUpdateInterval int               `json:"refresh" yaml:"refresh"`           // the interval in seconds to fetch data from data provider due to rate limit.
+	MaxSampleAge       int               `json:"max_age" yaml:"max_age"`           // the max distance in seconds of a sample from the target timestamp, 0 means unlimited.
+	Checksum           string            `json:"checksum" yaml:"checksum"`         // the hex encoded SHA-256 checksum of the plugin binary, it is verified before the plugin is started.
+	REST               *RESTConfig       `json:"rest,omitempty" yaml:"rest"`       // the data provider settings of the generic REST/JSON plugin.
+	Options            map[string]string `json:"options,omitempty" yaml:"options"` // the plugin specific options, e.g. the price side of an order book.
+	Symbols            map[string]string `json:"symbols,omitempty" yaml:"symbols"` // the symbol of the plugin by the symbol of the oracle server, it overrides the symbol conversion.
+}
+
+// Option returns the plugin specific option, or the default value if it is not set.
+func (c *PluginConfig) Option(name, def string) string {
+	if v, ok := c.Options[name]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// BoolOption returns the plugin specific option in boolean, or the default value if it is not set.
+func (c *PluginConfig) BoolOption(name string, def bool) (bool, error) {
+	v, ok := c.Options[name]
+	if !ok || v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def, fmt.Errorf("option %s of plugin %s is not a boolean: %s", name, c.Name, v)
+	}
+	return b, nil
+}
+
+// Diff returns the changes of the plugin configuration from c to n, the API key is redacted, the values of the options
+// are left out since a plugin can take a secret as an option, and the REST settings are compared as a whole since they
+// are templates carrying the key.
+func (c *PluginConfig) Diff(n *PluginConfig) []string {
+	var changes []string
+	diff := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", field, from, to))
+		}
+	}
+
+	if c.Key != n.Key {
+		changes = append(changes, fmt.Sprintf("key: %s -> %s", redact(c.Key), redact(n.Key)))
+	}
+	diff("key_file", c.KeyFile, n.KeyFile)
+	diff("scheme", c.Scheme, n.Scheme)
+	diff("endpoint", c.Endpoint, n.Endpoint)
+	diff("timeout", c.Timeout, n.Timeout)
+	diff("refresh", c.DataUpdateInterval, n.DataUpdateInterval)
+	diff("max_age", c.MaxSampleAge, n.MaxSampleAge)
+	diff("checksum", c.Checksum, n.Checksum)
+	if !reflect.DeepEqual(c.REST, n.REST) {
+		changes = append(changes, "rest: changed")
+	}
+	changes = append(changes, diffMap("options", c.Options, n.Options, true)...)
+	changes = append(changes, diffMap("symbols", c.Symbols, n.Symbols, false)...)
+	return changes
+}
+
+func diffMap(field string, from, to map[string]string, hideValues bool) []string {
+	keys := make(map[string]struct{})
+	for k := range from {
+		keys[k] = struct{}{}
+	}
+	for k := range to {
+		keys[k] = struct{}{}
+	}
+
+	var changes []string
+	for k := range keys {
+		f, inFrom := from[k]
+		t, inTo := to[k]
+		switch {
+		case !inFrom && hideValues:
+			changes = append(changes, fmt.Sprintf("%s.%s: added", field, k))
+		case !inFrom:
+			changes = append(changes, fmt.Sprintf("%s.%s: added %s", field, k, t))
+		case !inTo:
+			changes = append(changes, fmt.Sprintf("%s.%s: removed", field, k))
+		case f != t && hideValues:
+			changes = append(changes, fmt.Sprintf("%s.%s: changed", field, k))
+		case f != t:
+			changes = append(changes, fmt.Sprintf("%s.%s: %s -> %s", field, k, f, t))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return "<empty>"
+	}
+	return "***"
+}
+
+// The placements of the API key in the requests of the generic REST/JSON plugin.
+const (
+	AuthNone   = "none"
+	AuthHeader = "header"
+	AuthQuery  = "query"
+)
+
+// RESTConfig carry the settings of the generic REST/JSON plugin to query the prices from a data provider. The path, the
+// query values, the auth prefix and the selectors are templates, in which {symbol}, {base}, {quote}, {symbols} and {key}
+// are replaced by the symbol of the provider, the base and quote currencies of the symbol, the comma separated symbols
+// of the provider in a batch request, and the API key.
+type RESTConfig struct {
+	Path       string            `json:"path" yaml:"path"`               // the URL path of the request, e.g. api/v3/ticker/price.
+	Query      map[string]string `json:"query" yaml:"query"`             // the query parameters of the request.
+	Auth       string            `json:"auth" yaml:"auth"`               // the placement of the API key, none, header or query, default is none.
+	AuthName   string            `json:"auth_name" yaml:"auth_name"`     // the name of the header or of the query parameter carrying the key.
+	AuthPrefix string            `json:"auth_prefix" yaml:"auth_prefix"` // the prefix of the key in the header, e.g. "Bearer ".
+	Batch      bool              `json:"batch" yaml:"batch"`             // a single request serves all the symbols, otherwise a request is sent per symbol.
+	Price      string            `json:"price" yaml:"price"`             // the selector of the price in the response, e.g. rates.{base} or [symbol={symbol}].price.
+	Volume     string            `json:"volume" yaml:"volume"`           // the selector of the trading volume in the response, it is optional.
+	Invert     bool              `json:"invert" yaml:"invert"`           // the provider quotes the inverted rates, e.g. USD-EUR for EUR-USD.
+}
+
+// AggregatorConfig carry the aggregation strategy of a symbol with the parameters of the strategy.
+type AggregatorConfig struct {
+	Strategy string             `json:"strategy" yaml:"strategy"` // median, vwap, trimmed_mean, weighted or primary, default is median.
+	Trim     *float64           `json:"trim" yaml:"trim"`         // the fraction of samples to be dropped from each end by trimmed_mean, [0, 0.5), 0.2 if it is not set.
+	Weights  map[string]float64 `json:"weights" yaml:"weights"`   // the weight by plugin name for weighted, the unlisted plugins weight 1.
+	Primary  []string           `json:"primary" yaml:"primary"`   // the plugins in priority order for primary, the first available one wins.
+	Fallback string             `json:"fallback" yaml:"fallback"` // the strategy of primary once none of the primary plugins is available.
+	Outlier  *OutlierConfig     `json:"outlier" yaml:"outlier"`   // the outlier rejection of the symbol, it overrides the top level one.
+}
+
+// OutlierConfig carry the thresholds to drop the outlier samples before the aggregation, a zero threshold disables the check.
+type OutlierConfig struct {
+	MaxDeviation float64 `json:"max_deviation" yaml:"max_deviation"` // the max relative distance from the median of all the samples, e.g. 0.05 for 5%.
+	MADThreshold float64 `json:"mad_threshold" yaml:"mad_threshold"` // the max distance in MADs from the median of all the samples.
+	MaxJump      float64 `json:"max_jump" yaml:"max_jump"`           // the max relative distance from the last on-chain price of the symbol.
+}
+
+// DerivationConfig carry the rules to derive the prices of the symbols which are not provided by any plugin.
+type DerivationConfig struct {
+	Rules  map[string]string `json:"rules" yaml:"rules"`   // the expression by derived symbol, e.g. NTN-ATN: NTN-USD / ATN-USD.
+	Invert bool              `json:"invert" yaml:"invert"` // derive X-Y by 1 / Y-X for the symbols without a rule.
+	Pivots []string          `json:"pivots" yaml:"pivots"` // the pivot currencies to triangulate X-Y by X-P / Y-P for the symbols without a rule.
+}
+
+// AggregationConfig carry the default aggregation strategy and outlier rejection, the strategies by symbols, and the
+// derivation rules of the symbols.
+type AggregationConfig struct {
+	Default    AggregatorConfig            `json:"default" yaml:"default"`
+	Outlier    OutlierConfig               `json:"outlier" yaml:"outlier"`
+	Symbols    map[string]AggregatorConfig `json:"symbols" yaml:"symbols"`
+	Derivation DerivationConfig            `json:"derivation" yaml:"derivation"`
 }
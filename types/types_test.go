@@ -0,0 +1,43 @@
+package types
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPluginConfigDiff(t *testing.T) {
+	legacy := &PluginConfig{
+		Name:               "forex_currencyfreaks",
+		Key:                "575aab9e47e54790bf6d502c48407c10",
+		DataUpdateInterval: 30,
+		Options:            map[string]string{"price": "mid", "router": "api/orderbooks"},
+	}
+	require.Empty(t, legacy.Diff(legacy))
+
+	changed := &PluginConfig{
+		Name:               "forex_currencyfreaks",
+		Key:                "0be02ca33c4843ee968c4cedd2686f01",
+		DataUpdateInterval: 60,
+		REST:               &RESTConfig{Price: "price"},
+		Options:            map[string]string{"price": "bid", "derive": "false"},
+		Symbols:            map[string]string{"NTN-USD": "NTN-USDC"},
+	}
+	changes := legacy.Diff(changed)
+	require.Equal(t, []string{
+		"key: *** -> ***",
+		"refresh: 30 -> 60",
+		"rest: changed",
+		"options.derive: added",
+		"options.price: changed",
+		"options.router: removed",
+		"symbols.NTN-USD: added NTN-USDC",
+	}, changes)
+	for _, c := range changes {
+		require.NotContains(t, c, legacy.Key)
+		require.NotContains(t, c, changed.Key)
+	}
+
+	require.Equal(t, []string{"key: *** -> <empty>"}, legacy.Diff(&PluginConfig{
+		Name: legacy.Name, DataUpdateInterval: 30, Options: legacy.Options,
+	}))
+}